@@ -2,33 +2,35 @@ package main
 
 import (
 	"context"
-	"embed"
+	"errors"
 	"fmt"
-	"io/fs"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/andreimerlescu/figtree/v2"
 	"github.com/andreimerlescu/igo/internal"
 	"github.com/fatih/color"
 )
 
-//go:embed bundled/shim.go.sh
-var bundledShimsGoBytes embed.FS
-
-//go:embed bundled/shim.gofmt.sh
-var bundledShimsGofmtBytes embed.FS
-
 type Application struct {
 	ctx         context.Context
 	Figs        figtree.Plant
 	UserHomeDir string
 	Workspace   func() string
+
+	// releases caches the go.dev release index after fetchReleases
+	releases []Release
+	// releasesMu guards releases for the workers of installBatch
+	releasesMu sync.Mutex
+	// checksums pins the SHA-256 of tarballs by name, -import-lock fills it before
+	// installing the versions of the lock file
+	checksums map[string]string
 }
 
 var UserHomeDir = os.UserHomeDir
@@ -41,10 +43,14 @@ func NewApp() *Application {
 		UserHomeDir: userHomeDir,
 	}
 	app.Workspace = func() string {
-		if *app.Figs.Bool(kSystem) {
+		godir := *app.Figs.String(kGoDir)
+		if *app.Figs.Bool(kSystem) && godir == app.defaultGoDir() {
 			return filepath.Join("/", "usr", "go")
 		}
-		return filepath.Join(app.UserHomeDir, "go")
+		if abs, err := filepath.Abs(godir); err == nil {
+			return abs
+		}
+		return godir
 	}
 	app.Figs = figtree.With(figtree.Options{
 		ConfigFile: filepath.Join(app.UserHomeDir, ".igo.config.yml"),
@@ -56,19 +62,54 @@ func NewApp() *Application {
 	app.Figs.NewBool(cmdVersion, false, "Display version")
 	app.Figs.NewBool(cmdList, false, "Display installed versions")
 	app.Figs.NewBool(cmdEnv, false, "Display env")
-	app.Figs.NewString(cmdInstall, "", "Install a specific version of Go")
-	app.Figs.NewString(cmdUninstall, "", "Uninstall a specific version of Go")
-	app.Figs.NewString(cmdActivate, "", "Activate a specific version of Go")
+	app.Figs.NewBool(cmdDoctor, false, "Audit the workspace, shims and shell profiles")
+	app.Figs.NewBool(kRepair, false, "Repair what -doctor finds")
+	app.Figs.NewBool(cmdPrune, false, "Remove the versions, downloads and cache entries selected by -keep-patches, -unused-days, -prune-downloads and -prune-cache")
+	app.Figs.NewBool(kDryRun, false, "Show what -prune would remove and the disk space it would reclaim, or the drift that -sync would fix")
+	app.Figs.NewInt(kKeepPatches, 0, "Keep the newest N patch releases of each minor version of Go with -prune")
+	app.Figs.NewInt(kUnusedDays, 0, "Remove versions of Go unused for N days with -prune")
+	app.Figs.NewBool(kPruneDownloads, false, "Delete the downloaded tarballs with -prune")
+	app.Figs.NewBool(kPruneCache, false, "Trim the entries of GOCACHE unused for 5 days with -prune")
+	app.Figs.NewBool(cmdSync, false, "Install the versions and packages of -manifest that are missing and activate its default")
+	app.Figs.NewString(kManifest, filepath.Join(app.UserHomeDir, ".igo.manifest.yml"), "The manifest of the versions of Go, default version, extra packages and mirrors for -sync")
+	app.Figs.NewBool(kRemoveUnlisted, false, "Uninstall the versions of Go that -manifest does not list with -sync")
+	app.Figs.NewString(cmdExportLock, "", "Write the tarballs, checksums and package versions of every installed version of Go to this lock file, - for stdout")
+	app.Figs.NewString(cmdImportLock, "", "Install exactly the versions of Go and packages of this lock file and activate its default")
+	app.Figs.NewBool(cmdWhich, false, "Explain the version of Go that runs in the directory that follows, or the current directory")
+	app.Figs.NewBool(cmdCurrent, false, "Explain the version of Go that runs in the current directory (alias to -which)")
+	app.Figs.NewString(cmdPin, "", "Pin the current project to a version of Go in .go_version (X.Y.Z, X.Y, X.Y.x, ~X.Y, latest or stable)")
+	app.Figs.NewBool(kUnset, false, "Remove the .go_version pin of the current project")
+	app.Figs.NewBool(kToolchain, false, "Also set the toolchain directive of go.mod with -pin, or remove it with -unset")
+	app.Figs.NewString(cmdInit, "", "Print the shell integration for sh, bash, zsh or fish")
+	app.Figs.NewBool(kHook, true, "Add the hook that exports the version of Go of each directory to -init for bash, zsh and fish")
+	app.Figs.NewBool(kAutoInstall, false, "Let the -init hook and -pin install the version of Go that a directory asks for")
+	app.Figs.NewString(cmdHookEnv, "", "Print the exports of the version of Go for the current directory, used by the -init hook")
+	app.Figs.NewBool(kRC, false, "Add a managed block that loads -init to the rc file of the shell")
+	app.Figs.NewString(kRCFile, "", "The rc file of -rc and -rc-remove instead of the default of the shell")
+	app.Figs.NewBool(kRCRemove, false, "Remove the managed block of -init from the rc file of the shell")
+	app.Figs.NewBool(kProfiles, true, "Let install add exports to ~/.profile, ~/.bash_profile or ~/.zshrc.local when no -init block is managed")
+	app.Figs.NewBool(cmdRemote, false, "Display versions of Go available on go.dev for -goos and -goarch")
+	app.Figs.NewString(cmdInstall, "", "Install versions of Go separated by commas (X.Y.Z, X.Y, X.Y.x, ~X.Y, latest or stable)")
+	app.Figs.NewString(kInstallFile, "", "Install every version of Go listed in this file, one per line, with # comments")
+	app.Figs.NewInt(kJobs, 4, "How many versions of Go to download and extract at once when -i lists several")
+	app.Figs.NewString(cmdUninstall, "", "Uninstall an installed version of Go (X.Y.Z, X.Y, X.Y.x, ~X.Y or latest)")
+	app.Figs.NewString(cmdActivate, "", "Activate an installed version of Go (X.Y.Z, X.Y, X.Y.x, ~X.Y or latest)")
 	app.Figs.NewString(cmdFix, "", "Fix a specific version of Go")
-	app.Figs.NewString(cmdSwitch, "", "Switch to a specific version of Go")
+	app.Figs.NewString(cmdExec, "", "Run the command after -- with an installed version of Go without switching")
+	app.Figs.NewString(cmdSwitch, "", "Switch to an installed version of Go (X.Y.Z, X.Y, X.Y.x, ~X.Y or latest)")
 	app.Figs.NewBool(kSystem, false, "Install in system mode /usr/bin/go")
 	app.Figs.NewBool(kDebug, false, "Enable debug mode")
 	app.Figs.NewBool(kVerbose, false, "Enable verbose mode")
-	app.Figs.NewString(kGoDir, filepath.Join(app.UserHomeDir, "go"), "Path where you want multiple go versions installed")
+	app.Figs.NewString(kGoDir, app.defaultGoDir(), "Path where you want multiple go versions installed")
 	app.Figs.NewString(kGoos, runtime.GOOS, "Go OS")
 	app.Figs.NewString(kGoArch, runtime.GOARCH, "Go Architecture")
 	app.Figs.NewBool(kExtras, true, "Install extra packages")
-	app.Figs.NewMap(kExtraPackages, packages, "Extra packages to install")
+	app.Figs.NewMap(kExtraPackages, packages, "Extra packages to install as name=module[@version]")
+	app.Figs.NewString(kFormat, formatTable, "Output format of -l, -e, -version, -which, -doctor, -prune, -sync and several -i: table, plain, json or yaml")
+	app.Figs.NewList(kMirrors, []string{downloadBaseURL}, "Base URLs tried in order to download Go (https://, http:// or file://)")
+	app.Figs.NewString(kTarball, "", "Install -i from this local tarball instead of downloading it")
+	app.Figs.NewDuration(kLockTimeout, 10*time.Minute, "How long to wait for another igo to release its install lock")
+	app.Figs.NewString(kSHA256, "", "Expected SHA-256 of the tarball, required when go.dev and -mirrors publish none and must match theirs otherwise")
 	_, err = os.Lstat(figtree.ConfigFilePath)
 	if os.IsNotExist(err) || os.IsPermission(err) {
 		internal.Capture(app.Figs.Parse())
@@ -78,76 +119,108 @@ func NewApp() *Application {
 	return app
 }
 
-// Add this function to application.go to validate Go version formats
+// defaultGoDir returns the IGO_GODIR environment variable or ~/go when it is not set
+func (app *Application) defaultGoDir() string {
+	if godir := os.Getenv(envGoDir); len(godir) > 0 {
+		return godir
+	}
+	return filepath.Join(app.UserHomeDir, "go")
+}
+
+// validateVersion checks the format of a Go version and that it is supported
 func (app *Application) validateVersion(version string) error {
 	// Basic format check with regex
-	if !regexp.MustCompile(`^\d+\.\d+\.\d+$`).MatchString(version) {
-		return fmt.Errorf("invalid go version format: %s (expected format: X.Y.Z)", version)
+	if !isExactVersion(version) {
+		return fmt.Errorf("invalid go version format: %s (expected format: X.Y.Z or X.YrcN / X.YbetaN)", version)
 	}
 
 	// Parse version parts to check they're valid numbers
-	var major, minor, patch int
-	_, err := fmt.Sscanf(version, VersionFmt, &major, &minor, &patch)
-	if err != nil {
-		return fmt.Errorf("error parsing version components: %w", err)
+	v, ok := parseGoVersion(version)
+	if !ok {
+		return fmt.Errorf("error parsing version components: %s", version)
 	}
 
 	// Optional: Add constraints on minimum supported versions
-	if major < 1 || (major == 1 && minor < 16) {
+	if v.Major < 1 || (v.Major == 1 && v.Minor < 16) {
 		return fmt.Errorf("go version %s is not supported (minimum: 1.16.0)", version)
 	}
 
-	// Verify the version exists on Go's download server before proceeding
-	// Using a HEAD request to check if the URL exists
-	resp, err := httpHead(fmt.Sprintf("https://go.dev/dl/go%s.%s-%s.tar.gz",
-		version, *app.Figs.String(kGoos), *app.Figs.String(kGoArch)))
-	if err != nil {
-		return fmt.Errorf("error checking if version exists: %w", err)
+	// A local tarball is verified by its checksum instead of the download server
+	if len(*app.Figs.String(kTarball)) > 0 {
+		return nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("go version %s not found on download server (status: %d)",
-			version, resp.StatusCode)
+	// Verify the version exists on one of the mirrors before proceeding
+	// Using a HEAD request to check if the URL exists
+	tarball := fmt.Sprintf("go%s.%s-%s.tar.gz", version, *app.Figs.String(kGoos), *app.Figs.String(kGoArch))
+	var errs []error
+	for _, mirror := range app.mirrors() {
+		err := mirrorHas(mirror, tarball)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
 	}
-
-	return nil
+	return fmt.Errorf("go version %s not found on download server: %w", version, errors.Join(errs...))
 }
 
-// CreateShims creates the shims for go and gofmt
+// CreateShims links the shims for go and gofmt to the igo binary, which resolves
+// the version of go to run when it is executed by one of those names
 func (app *Application) CreateShims() error {
-	workspace := app.Workspace()
-	shimsDir := filepath.Join(workspace, "shims")
-	goShim := filepath.Join(shimsDir, "go")
-	gofmtShim := filepath.Join(shimsDir, "gofmt")
-	shimGoBytes, err := bundledShimsGoBytes.ReadFile("bundled/shim.go.sh")
+	shimsDir := filepath.Join(app.Workspace(), "shims")
+	self, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to read bundled shim.go.sh: %v", err)
+		return fmt.Errorf("failed to find the igo executable: %w", err)
 	}
-	err = os.WriteFile(goShim, shimGoBytes, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to write shim.go.sh: %v", err)
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
 	}
-	shimGofmtBytes, err := bundledShimsGofmtBytes.ReadFile("bundled/shim.gofmt.sh")
-	if err != nil {
-		return fmt.Errorf("failed to read bundled shim.go.sh: %v", err)
-	}
-	err = os.WriteFile(gofmtShim, shimGofmtBytes, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to write shim.gofmt.sh: %v", err)
+	for _, name := range shimNames {
+		shim := filepath.Join(shimsDir, name)
+		if target, err := os.Readlink(shim); err == nil && target == self {
+			continue
+		}
+		if err := os.Remove(shim); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove shim %s: %w", shim, err)
+		}
+		if err := os.Symlink(self, shim); err != nil {
+			return fmt.Errorf("failed to link shim %s: %w", shim, err)
+		}
 	}
-	internal.Capture(os.Chmod(goShim, 0755))
-	internal.Capture(os.Chmod(gofmtShim, 0755))
 	return nil
 }
 
+// versionPaths returns GOROOT, GOPATH, GOBIN and GOMODCACHE of an installed version of
+// go pointing directly into versions/<version> instead of the bin, path and root symlinks
+func versionPaths(workspace, version string) map[string]string {
+	versionDir := filepath.Join(workspace, "versions", version)
+	return map[string]string{
+		GOBIN:      filepath.Join(versionDir, "go", "bin"),
+		GOROOT:     filepath.Join(versionDir, "go"),
+		GOPATH:     versionDir,
+		GOMODCACHE: filepath.Join(versionDir, "go", "pkg", "mod"),
+	}
+}
+
+// versionEnvs returns the environment of an installed version of go built on versionPaths
+func (app *Application) versionEnvs(version string) map[string]string {
+	workspace := app.Workspace()
+	envs := versionPaths(workspace, version)
+	envs[GOOS] = *app.Figs.String(kGoos)
+	envs[GOARCH] = *app.Figs.String(kGoArch)
+	envs[GOSCRIPTS] = filepath.Join(workspace, "scripts")
+	envs[GOSHIMS] = filepath.Join(workspace, "shims")
+	envs[GOCACHE] = filepath.Join(workspace, "cache")
+	envs[GOTELEMETRYDIR] = filepath.Join(workspace, "telemetry")
+	return envs
+}
+
 // runVersionCheck executes "go version" with specified environment variables and returns the output.
-// Panics if an error occurs.
-func (app *Application) runVersionCheck(envs map[string]string, version string) string {
+func (app *Application) runVersionCheck(envs map[string]string, version string) (string, error) {
 	goBinPath := filepath.Join(app.Workspace(), "versions", version, "go", "bin", fmt.Sprintf("go.%s", version))
 
 	if _, err := os.Stat(goBinPath); os.IsNotExist(err) {
-		internal.Capture(fmt.Errorf("go binary does not exist at %s: %v", goBinPath, err))
+		return "", fmt.Errorf("go binary does not exist at %s: %v", goBinPath, err)
 	}
 
 	cmdEnv := []string{
@@ -164,47 +237,99 @@ func (app *Application) runVersionCheck(envs map[string]string, version string)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		internal.Capture(fmt.Errorf("failed to execute 'go version' with %s: %v\nOutput: %s", goBinPath, err, string(output)))
+		return "", fmt.Errorf("failed to execute 'go version' with %s: %v\nOutput: %s", goBinPath, err, string(output))
 	}
 	gover := strings.TrimSpace(string(output))
 	if *app.Figs.Bool(kVerbose) {
 		color.Green("Received terminal output: %s", gover)
 	}
 
-	return gover
+	return gover, nil
 }
 
-// installExtraPackages installs additional Go packages using the specified environment and version.
+// extraPackage is a tool from -extra-packages that is installed with go install
+type extraPackage struct {
+	// Name is the binary that go install produces
+	Name string
+	// Module is the package path passed to go install
+	Module string
+	// Version is the pinned version or latest
+	Version string
+}
+
+// extraPackageSpecs parses the -extra-packages map of name=module[@version] into
+// extraPackage entries sorted by name
+func extraPackageSpecs(specs map[string]string) []extraPackage {
+	var pkgs []extraPackage
+	for name, spec := range specs {
+		name, spec = strings.TrimSpace(name), strings.TrimSpace(spec)
+		if len(name) == 0 || len(spec) == 0 {
+			continue
+		}
+		module, version, found := strings.Cut(spec, "@")
+		if !found || len(version) == 0 {
+			version = "latest"
+		}
+		pkgs = append(pkgs, extraPackage{Name: name, Module: module, Version: version})
+	}
+	slices.SortFunc(pkgs, func(a, b extraPackage) int { return strings.Compare(a.Name, b.Name) })
+	return pkgs
+}
+
+// installExtraPackages installs the -extra-packages using the specified environment and version.
+// Every package is attempted and the failures are returned together once all are done.
 func (app *Application) installExtraPackages(envs map[string]string, version string) error {
+	if !*app.Figs.Bool(kExtras) {
+		color.Yellow("Skipping extra packages (-%s=false)", kExtras)
+		return nil
+	}
+	return app.installPackages(envs, version, extraPackageSpecs(*app.Figs.Map(kExtraPackages)))
+}
+
+// installPackages runs go install for each of pkgs with version into its go/bin directory.
+// Every package is attempted and the failures are returned together once all are done.
+func (app *Application) installPackages(envs map[string]string, version string, pkgs []extraPackage) error {
 	workspace := app.Workspace()
-	goBinPath := filepath.Join(workspace, "versions", version, "go", "bin", fmt.Sprintf("go.%s", version))
+	goBinDir := filepath.Join(workspace, "versions", version, "go", "bin")
+	goBinPath := filepath.Join(goBinDir, fmt.Sprintf("go.%s", version))
 	if _, err := os.Stat(goBinPath); os.IsNotExist(err) {
 		return fmt.Errorf("go binary does not exist at %s: %w", goBinPath, err)
 	}
 	cmdEnv := []string{
 		fmt.Sprintf("GOROOT=%s", filepath.Join(workspace, "versions", version, "go")),
 		fmt.Sprintf("GOPATH=%s", filepath.Join(workspace, "versions", version)),
-		fmt.Sprintf("GOBIN=%s", filepath.Join(workspace, "versions", version, "go", "bin")),
+		fmt.Sprintf("GOBIN=%s", goBinDir),
 		fmt.Sprintf("GOOS=%s", envs[GOOS]),
 		fmt.Sprintf("GOARCH=%s", envs[GOARCH]),
 	}
-	p := app.Figs.Fig(kExtraPackages).ToString()
-	color.Green("Installing extra packages: %s", p)
+	if len(pkgs) == 0 {
+		return nil
+	}
+	color.Green("Installing %d extra packages", len(pkgs))
 
-	for pkg, modulePath := range packages {
-		cmd := exec.Command(goBinPath, "install", fmt.Sprintf("%s@latest", modulePath))
+	var installed []string
+	var failures []error
+	for _, pkg := range pkgs {
+		target := fmt.Sprintf("%s@%s", pkg.Module, pkg.Version)
+		cmd := exec.Command(goBinPath, "install", target)
 		cmd.Env = append(os.Environ(), cmdEnv...) // Include existing env vars plus custom ones
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			return fmt.Errorf("failed to install %s (%s): %w\nOutput: %s", pkg, modulePath, err, string(output))
+			color.Red("Failed to install %s (%s)", pkg.Name, target)
+			failures = append(failures, fmt.Errorf("failed to install %s (%s): %w\nOutput: %s", pkg.Name, target, err, string(output)))
+			continue
 		}
-		binPath := filepath.Join(envs[GOBIN], pkg)
+		binPath := filepath.Join(goBinDir, pkg.Name)
 		if _, err := os.Stat(binPath); os.IsNotExist(err) {
-			return fmt.Errorf("installation of %s succeeded but binary not found at %s", pkg, binPath)
+			color.Red("Installed %s but binary not found at %s", pkg.Name, binPath)
+			failures = append(failures, fmt.Errorf("installation of %s succeeded but binary not found at %s", pkg.Name, binPath))
+			continue
 		}
-		color.Green("Installed %s successfully", pkg)
+		installed = append(installed, pkg.Name)
+		color.Green("Installed %s (%s) successfully", pkg.Name, target)
 	}
-	return nil
+	color.Green("Extra packages: %d installed, %d failed", len(installed), len(failures))
+	return errors.Join(failures...)
 }
 
 // patchShellConfigPath updates the shell config file to ensure PATH includes specific directories.
@@ -230,9 +355,11 @@ func (app *Application) patchShellConfigPath(envs map[string]string) error {
 		}
 	}
 	if targetFile == "" {
-		contents := fmt.Sprintf("export PATH=%s:%s:%s:%s\n",
-			envs[GOSHIMS], envs[GOSCRIPTS], envs[GOBIN], os.Getenv("PATH"))
-		internal.Capture(os.WriteFile(zshrc, []byte(contents), 0644))
+		contents := fmt.Sprintf("export PATH=%s:%s:%s:$PATH\n",
+			envs[GOSHIMS], envs[GOSCRIPTS], envs[GOBIN])
+		if err := os.WriteFile(zshrc, []byte(contents), 0644); err != nil {
+			return err
+		}
 		return os.WriteFile(bashrc, []byte(contents), 0644)
 	}
 
@@ -286,14 +413,14 @@ func (app *Application) patchShellConfigPath(envs map[string]string) error {
 			if err != nil {
 				return fmt.Errorf("214 failed to write file %s: %w", targetFile, err)
 			}
-			fmt.Printf("Updated PATH in %s with missing paths: %v\n", targetFile, missingPaths)
+			fmt.Fprintf(color.Output, "Updated PATH in %s with missing paths: %v\n", targetFile, missingPaths)
 		} else {
-			fmt.Printf("PATH in %s already contains all required paths\n", targetFile)
+			fmt.Fprintf(color.Output, "PATH in %s already contains all required paths\n", targetFile)
 		}
 		return nil
 	}
 
-	newPathLine := fmt.Sprintf("export PATH=%s:%s:%s:%s", envs[GOSHIMS], envs[GOBIN], envs[GOSCRIPTS], os.Getenv("PATH"))
+	newPathLine := fmt.Sprintf("export PATH=%s:%s:%s:$PATH", envs[GOSHIMS], envs[GOBIN], envs[GOSCRIPTS])
 	targetHandler, err := os.OpenFile(targetFile, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("226 could not open target file: %w", err)
@@ -316,30 +443,28 @@ func (app *Application) patchShellConfigPath(envs map[string]string) error {
 
 // findGoVersions returns installed versions of Go in the igoWorkspace()
 func (app *Application) findGoVersions() ([]string, error) {
+	return installedVersions(app.Workspace())
+}
+
+// installedVersions returns the versions of Go in the versions directory of the
+// workspace from oldest to newest
+func installedVersions(workspace string) ([]string, error) {
 	var versions []string
-	d := app.Workspace()
-	dvs := filepath.Join(d, "versions")
-	internal.Capture(filepath.WalkDir(d, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			p := filepath.Base(path)
-			for _, version := range versions {
-				versionDir := filepath.Join(dvs, version)
-				if strings.HasPrefix(path, versionDir) {
-					return nil // skip over file in this version directory
-				}
-			}
-			var vMaj, vMin, vPat int
-			_, _ = fmt.Sscanf(p, VersionFmt, &vMaj, &vMin, &vPat)
-			if vMaj == 0 && vMin == 0 && vPat == 0 {
-				return nil // silent skip over non-version directory
-			}
-			versions = append(versions, fmt.Sprintf(VersionFmt, vMaj, vMin, vPat))
+	dvs := filepath.Join(workspace, "versions")
+	entries, err := os.ReadDir(dvs)
+	if os.IsNotExist(err) {
+		return versions, nil
+	}
+	if err != nil {
+		return nil, internal.ErrDirEntries{Path: dvs, Err: err}
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !isExactVersion(entry.Name()) {
+			continue // silent skip over non-version directory
 		}
-		return nil
-	}))
+		versions = append(versions, entry.Name())
+	}
+	sortGoVersions(versions)
 	return versions, nil
 }
 
@@ -355,6 +480,16 @@ func (app *Application) activatedVersion() (string, error) {
 	return s, nil
 }
 
+// shellConfigFiles are the shell profiles that injectEnvVarsToShellConfig and
+// patchShellConfigPath may create or modify
+func (app *Application) shellConfigFiles() []string {
+	return []string{
+		filepath.Join(app.UserHomeDir, ".profile"),
+		filepath.Join(app.UserHomeDir, ".bash_profile"),
+		filepath.Join(app.UserHomeDir, ".zshrc.local"),
+	}
+}
+
 // injectEnvVarsToShellConfig will take the map of envs and add them to the bashrc or zshrc file as export ENV=val
 func (app *Application) injectEnvVarsToShellConfig(envs map[string]string) error {
 	// Possible shell config files to check
@@ -431,11 +566,11 @@ func (app *Application) injectEnvVarsToShellConfig(envs map[string]string) error
 			}
 		}
 
-		fmt.Printf("Updated %s with %d new environment variables\n", targetFile, len(newLines))
+		fmt.Fprintf(color.Output, "Updated %s with %d new environment variables\n", targetFile, len(newLines))
 
 		return shellProfileFile.Close()
 	} else {
-		fmt.Printf("No new environment variables to add to %s\n", targetFile)
+		fmt.Fprintf(color.Output, "No new environment variables to add to %s\n", targetFile)
 	}
 
 	return nil
@@ -44,6 +44,8 @@ func TestApplication_validateVersion(t *testing.T) {
 	}
 	app.Figs.NewString(kGoos, "linux", "")
 	app.Figs.NewString(kGoArch, "amd64", "")
+	app.Figs.NewList(kMirrors, []string{downloadBaseURL}, "")
+	app.Figs.NewString(kTarball, "", "")
 
 	t.Run("valid version", func(t *testing.T) {
 		originalHttpHead := http.Head
@@ -59,6 +61,21 @@ func TestApplication_validateVersion(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("valid pre-release", func(t *testing.T) {
+		originalHttpHead := http.Head
+		httpHead = func(url string) (resp *http.Response, err error) {
+			assert.Contains(t, url, "go1.25rc1.linux-amd64.tar.gz")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}
+		defer func() { httpHead = originalHttpHead }()
+
+		err := app.validateVersion("1.25rc1")
+		assert.NoError(t, err)
+	})
+
 	t.Run("invalid format", func(t *testing.T) {
 		err := app.validateVersion("invalid")
 		assert.Error(t, err)
@@ -85,6 +102,25 @@ func TestApplication_validateVersion(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found on download server")
 	})
+
+	t.Run("file mirror", func(t *testing.T) {
+		mirrorDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, "go1.21.0.linux-amd64.tar.gz"), nil, 0644))
+		app.Figs.StoreList(kMirrors, []string{"https://mirror.invalid/go", "file://" + mirrorDir})
+		defer app.Figs.StoreList(kMirrors, []string{downloadBaseURL})
+		originalHttpHead := http.Head
+		httpHead = func(url string) (resp *http.Response, err error) {
+			assert.True(t, strings.HasPrefix(url, "https://mirror.invalid/go/go1.21."), url)
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}
+		defer func() { httpHead = originalHttpHead }()
+
+		assert.NoError(t, app.validateVersion("1.21.0"))
+		assert.Error(t, app.validateVersion("1.21.1"))
+	})
 }
 
 func TestApplication_Workspace(t *testing.T) {
@@ -99,6 +135,38 @@ func TestApplication_Workspace(t *testing.T) {
 	})
 }
 
+func TestApplication_Workspace_godir(t *testing.T) {
+	tempDir := t.TempDir()
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	UserHomeDir = func() (string, error) { return tempDir, nil }
+
+	t.Run("default godir", func(t *testing.T) {
+		os.Args = []string{os.Args[0]}
+		app := NewApp()
+		assert.Equal(t, filepath.Join(tempDir, "go"), app.Workspace())
+	})
+
+	t.Run("custom godir", func(t *testing.T) {
+		os.Args = []string{os.Args[0], "-" + kGoDir, "/Shared/go"}
+		app := NewApp()
+		assert.Equal(t, "/Shared/go", app.Workspace())
+	})
+
+	t.Run("system godir", func(t *testing.T) {
+		os.Args = []string{os.Args[0], "-" + kSystem}
+		app := NewApp()
+		assert.Equal(t, "/usr/go", app.Workspace())
+	})
+
+	t.Run("IGO_GODIR", func(t *testing.T) {
+		t.Setenv(envGoDir, "/opt/toolchains")
+		os.Args = []string{os.Args[0]}
+		app := NewApp()
+		assert.Equal(t, "/opt/toolchains", app.Workspace())
+	})
+}
+
 func TestApplication_CreateShims(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "igo-shim-test")
 	require.NoError(t, err)
@@ -125,22 +193,25 @@ func TestApplication_CreateShims(t *testing.T) {
 	err = app.CreateShims()
 	assert.NoError(t, err)
 
-	goShimPath := filepath.Join(shimsDir, "go")
-	gofmtShimPath := filepath.Join(shimsDir, "gofmt")
-
-	_, err = os.Stat(goShimPath)
-	assert.NoError(t, err, "go shim should exist")
-
-	_, err = os.Stat(gofmtShimPath)
-	assert.NoError(t, err, "gofmt shim should exist")
-
-	goShimInfo, err := os.Stat(goShimPath)
+	self, err := os.Executable()
 	require.NoError(t, err)
-	assert.Equal(t, os.FileMode(0755), goShimInfo.Mode().Perm())
-
-	gofmtShimInfo, err := os.Stat(gofmtShimPath)
+	self, err = filepath.EvalSymlinks(self)
 	require.NoError(t, err)
-	assert.Equal(t, os.FileMode(0755), gofmtShimInfo.Mode().Perm())
+
+	for _, name := range []string{"go", "gofmt"} {
+		shim := filepath.Join(shimsDir, name)
+		target, err := os.Readlink(shim)
+		assert.NoError(t, err, "%s shim should be a symlink", name)
+		assert.Equal(t, self, target)
+	}
+
+	// re-creating the shims replaces stale bash shims with the symlinks
+	require.NoError(t, os.Remove(filepath.Join(shimsDir, "go")))
+	require.NoError(t, os.WriteFile(filepath.Join(shimsDir, "go"), []byte("#!/bin/bash"), 0755))
+	require.NoError(t, app.CreateShims())
+	target, err := os.Readlink(filepath.Join(shimsDir, "go"))
+	assert.NoError(t, err)
+	assert.Equal(t, self, target)
 }
 
 func TestApplication_findGoVersions(t *testing.T) {
@@ -151,6 +222,7 @@ func TestApplication_findGoVersions(t *testing.T) {
 	versionsDir := filepath.Join(tempDir, "versions")
 	require.NoError(t, os.MkdirAll(filepath.Join(versionsDir, "1.20.0"), 0755))
 	require.NoError(t, os.MkdirAll(filepath.Join(versionsDir, "1.21.3"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(versionsDir, "1.25rc1"), 0755))
 	require.NoError(t, os.MkdirAll(filepath.Join(versionsDir, "non-version-dir"), 0755))
 
 	app := &Application{
@@ -170,6 +242,7 @@ func TestApplication_findGoVersions(t *testing.T) {
 
 	assert.Contains(t, versions, "1.20.0")
 	assert.Contains(t, versions, "1.21.3")
+	assert.Contains(t, versions, "1.25rc1")
 	assert.NotContains(t, versions, "non-version-dir")
 }
 
@@ -204,3 +277,103 @@ func TestApplication_activatedVersion(t *testing.T) {
 	_, err = app.activatedVersion()
 	assert.Error(t, err)
 }
+
+func TestExtraPackageSpecs(t *testing.T) {
+	pkgs := extraPackageSpecs(map[string]string{
+		"staticcheck": "honnef.co/go/tools/cmd/staticcheck@2024.1.1",
+		"gopls":       "golang.org/x/tools/gopls",
+		"dlv":         "github.com/go-delve/delve/cmd/dlv@",
+		"":            "ignored",
+	})
+	assert.Equal(t, []extraPackage{
+		{Name: "dlv", Module: "github.com/go-delve/delve/cmd/dlv", Version: "latest"},
+		{Name: "gopls", Module: "golang.org/x/tools/gopls", Version: "latest"},
+		{Name: "staticcheck", Module: "honnef.co/go/tools/cmd/staticcheck", Version: "2024.1.1"},
+	}, pkgs)
+}
+
+func TestApplication_installExtraPackages(t *testing.T) {
+	tempDir := t.TempDir()
+	binDir := filepath.Join(tempDir, "versions", "1.24.3", "go", "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	// the fake go binary installs everything except modules containing "broken"
+	fakeGo := "#!/bin/sh\ncase \"$2\" in *broken*) echo broken >&2; exit 1;; esac\n" +
+		"name=\"${2%%@*}\"; touch \"$GOBIN/${name##*/}\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "go.1.24.3"), []byte(fakeGo), 0755))
+
+	os.Args = []string{os.Args[0]}
+	app := NewApp()
+	app.Workspace = func() string { return tempDir }
+	app.Figs.StoreMap(kExtraPackages, map[string]string{
+		"gopls":  "golang.org/x/tools/gopls@v0.16.0",
+		"broken": "example.com/broken",
+		"dlv":    "github.com/go-delve/delve/cmd/dlv",
+	})
+	envs := map[string]string{GOOS: "linux", GOARCH: "amd64"}
+
+	t.Run("continues past failures", func(t *testing.T) {
+		err := app.installExtraPackages(envs, "1.24.3")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to install broken")
+		assert.FileExists(t, filepath.Join(binDir, "gopls"))
+		assert.FileExists(t, filepath.Join(binDir, "dlv"))
+	})
+
+	t.Run("respects -extras=false", func(t *testing.T) {
+		app.Figs.StoreBool(kExtras, false)
+		defer app.Figs.StoreBool(kExtras, true)
+		assert.NoError(t, app.installExtraPackages(envs, "1.24.3"))
+	})
+}
+
+func TestApplication_versionEnvs(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Args = []string{os.Args[0], "-" + kGoos, "linux", "-" + kGoArch, "arm64"}
+	app := NewApp()
+	app.Workspace = func() string { return tempDir }
+
+	envs := app.versionEnvs("1.22.5")
+	versionDir := filepath.Join(tempDir, "versions", "1.22.5")
+	assert.Equal(t, filepath.Join(versionDir, "go"), envs[GOROOT])
+	assert.Equal(t, versionDir, envs[GOPATH])
+	assert.Equal(t, filepath.Join(versionDir, "go", "bin"), envs[GOBIN])
+	assert.Equal(t, filepath.Join(versionDir, "go", "pkg", "mod"), envs[GOMODCACHE])
+	assert.Equal(t, filepath.Join(tempDir, "cache"), envs[GOCACHE])
+	assert.Equal(t, filepath.Join(tempDir, "shims"), envs[GOSHIMS])
+	assert.Equal(t, "linux", envs[GOOS])
+	assert.Equal(t, "arm64", envs[GOARCH])
+}
+
+func TestApplication_execEnvs(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Args = []string{os.Args[0]}
+	app := NewApp()
+	app.Workspace = func() string { return tempDir }
+	// the shell profiles that install writes export these before -exec runs
+	t.Setenv(envVersion, "1.21.0")
+	t.Setenv(GOROOT, filepath.Join(tempDir, "root"))
+	t.Setenv("PATH", "/usr/bin")
+
+	environ := mergeEnviron(os.Environ(), app.execEnvs("1.22.5"))
+	versionDir := filepath.Join(tempDir, "versions", "1.22.5")
+	assert.Equal(t, "1.22.5", firstEnv(environ, envVersion))
+	assert.Equal(t, filepath.Join(versionDir, "go"), firstEnv(environ, GOROOT))
+	assert.Equal(t, strings.Join([]string{filepath.Join(tempDir, "shims"), filepath.Join(versionDir, "go", "bin"), "/usr/bin"},
+		string(filepath.ListSeparator)), firstEnv(environ, "PATH"))
+}
+
+func TestMergeEnviron(t *testing.T) {
+	got := mergeEnviron([]string{"HOME=/home/me", "GOROOT=/ws/root", "GOROOT=/other"}, map[string]string{"GOROOT": "/ws/versions/1.22.1/go"})
+	assert.Equal(t, []string{"HOME=/home/me", "GOROOT=/ws/versions/1.22.1/go"}, got)
+}
+
+// firstEnv returns the value of the first entry of name in environ, the one that a
+// program started with environ reads
+func firstEnv(environ []string, name string) string {
+	for _, entry := range environ {
+		if key, value, _ := strings.Cut(entry, "="); key == name {
+			return value
+		}
+	}
+	return ""
+}
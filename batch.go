@@ -0,0 +1,222 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// statuses of an installRecord
+const (
+	installInstalled = "installed"
+	installSkipped   = "already installed"
+	installFailed    = "failed"
+)
+
+// installRecord is the outcome of installing one version of go with -i for -format
+type installRecord struct {
+	Selector string        `json:"selector" yaml:"selector"`
+	Version  string        `json:"version,omitempty" yaml:"version,omitempty"`
+	Status   string        `json:"status" yaml:"status"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+	err      error
+}
+
+// splitSelectors returns the selectors in s separated by commas or whitespace
+func splitSelectors(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// readInstallFile returns the selectors in path, one or more per line, where # starts
+// a comment
+func readInstallFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, internal.ErrFile{Path: path, Err: err, How: "os.ReadFile"}
+	}
+	var selectors []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		selectors = append(selectors, splitSelectors(line)...)
+	}
+	return selectors, nil
+}
+
+// installSelectors returns the selectors of -i and -install-file
+func (app *Application) installSelectors() ([]string, error) {
+	selectors := splitSelectors(*app.Figs.String(cmdInstall))
+	if file := *app.Figs.String(kInstallFile); len(file) > 0 {
+		listed, err := readInstallFile(file)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, listed...)
+	}
+	if len(selectors) == 0 {
+		return nil, internal.ErrUsage{Usage: fmt.Sprintf("-%s <version>[,<version>...] | -%s <file> [-%s N]", cmdInstall, kInstallFile, kJobs)}
+	}
+	return selectors, nil
+}
+
+// isBatchInstall reports whether -i lists several versions or -install-file is set
+func (app *Application) isBatchInstall() bool {
+	return len(splitSelectors(*app.Figs.String(cmdInstall))) > 1 || len(*app.Figs.String(kInstallFile)) > 0
+}
+
+// installBatch installs every version of go that the selectors of -i and -install-file
+// resolve to with up to -jobs at once, one version that fails does not stop the others
+// and the version that the last listed new install activated stays activated
+func installBatch(app *Application) error {
+	if len(*app.Figs.String(kTarball)) > 0 || len(*app.Figs.String(kSHA256)) > 0 {
+		return internal.ErrUsage{Usage: fmt.Sprintf("-%s and -%s install a single version with -%s", kTarball, kSHA256, cmdInstall)}
+	}
+	format, err := app.outputFormat()
+	if err != nil {
+		return err
+	}
+	defer reportOnStderr(format)()
+	selectors, err := app.installSelectors()
+	if err != nil {
+		return err
+	}
+	// resolve every selector first so that a typo fails before anything is downloaded
+	var records []installRecord
+	seen := map[string]bool{}
+	for _, selector := range selectors {
+		record := installRecord{Selector: selector}
+		version, err := app.resolveSelector("install", selector)
+		if err == nil {
+			err = app.validateVersion(version)
+		}
+		if err != nil {
+			record.Status, record.err = installFailed, internal.ErrBadVersion{Version: selector, Err: err}
+			records = append(records, record)
+			continue
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		record.Version = version
+		records = append(records, record)
+	}
+	app.installRecords(records)
+	// every install activates its version when it finishes, activate the one that
+	// running the installs one after the other would have left activated
+	var errs []error
+	activate := ""
+	for i := range records {
+		if records[i].err != nil {
+			records[i].Error = records[i].err.Error()
+			if len(records[i].Version) > 0 {
+				errs = append(errs, fmt.Errorf("go %s: %w", records[i].Version, records[i].err))
+			} else {
+				errs = append(errs, records[i].err)
+			}
+		}
+		if records[i].Status == installInstalled {
+			activate = records[i].Version
+		}
+	}
+	if active, _ := app.activatedVersion(); len(activate) > 0 && active != activate {
+		if err := use(app, activate); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := showInstallSummary(format, records); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// installRecords installs the version of every record that has no status yet with up
+// to -jobs at once and records the outcome of each
+func (app *Application) installRecords(records []installRecord) {
+	workspace := app.Workspace()
+	jobs := max(1, *app.Figs.Int(kJobs))
+	// the bars of parallel downloads would overwrite each other
+	lines := progressLines
+	progressLines = lines || jobs > 1
+	defer func() { progressLines = lines }()
+	queue := make(chan *installRecord)
+	var wg sync.WaitGroup
+	for range min(jobs, len(records)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range queue {
+				start := time.Now()
+				_, statErr := os.Stat(filepath.Join(workspace, "versions", record.Version, "installer.lock"))
+				if err := install(app, record.Version); err != nil {
+					record.Status, record.err = installFailed, err
+				} else if statErr == nil {
+					record.Status = installSkipped
+				} else {
+					record.Status = installInstalled
+				}
+				record.Duration = time.Since(start).Round(time.Millisecond)
+			}
+		}()
+	}
+	for i := range records {
+		if len(records[i].Status) == 0 {
+			queue <- &records[i]
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// reportOnStderr sends the color output and download progress to stderr while stdout
+// carries the json or yaml records of format, the returned func puts them back
+func reportOnStderr(format string) func() {
+	if format != formatJSON && format != formatYAML {
+		return func() {}
+	}
+	output, lines := color.Output, progressLines
+	color.Output, progressLines = os.Stderr, true
+	return func() { color.Output, progressLines = output, lines }
+}
+
+// showInstallSummary prints the outcome of each version of installBatch
+func showInstallSummary(format string, records []installRecord) error {
+	switch format {
+	case formatJSON, formatYAML:
+		return writeRecords(os.Stdout, format, records)
+	case formatPlain:
+		for _, record := range records {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", record.Selector, record.Version, record.Status, record.Duration, record.Error)
+		}
+		return nil
+	}
+	installed := 0
+	for _, record := range records {
+		if record.Status == installInstalled {
+			installed++
+		}
+	}
+	color.Magenta("Installed %d of %d versions of go", installed, len(records))
+	for _, record := range records {
+		version := cmp.Or(record.Version, record.Selector)
+		switch record.Status {
+		case installInstalled:
+			color.Green("  go %s installed in %s", version, record.Duration)
+		case installSkipped:
+			color.Green("  go %s was already installed", version)
+		default:
+			color.Red("  go %s failed: %v", version, record.err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInstallFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# toolchains for CI\n1.22.x\n\n1.23.x, 1.24.3 # newest\n"), 0644))
+	selectors, err := readInstallFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.22.x", "1.23.x", "1.24.3"}, selectors)
+	assert.Equal(t, []string{"1.22.x", "1.23.x"}, splitSelectors(" 1.22.x,,1.23.x "))
+}
+
+func TestInstallBatch(t *testing.T) {
+	home := t.TempDir()
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	UserHomeDir = func() (string, error) { return home, nil }
+	mirrors, _ := fileMirror(t, "1.20.0", "1.21.0")
+	workspace := filepath.Join(home, "go")
+	os.Args = []string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64", "-extras=false",
+		"-profiles=false", "-mirrors", mirrors, "-jobs", "2", "-format", formatJSON,
+		"-i", "1.20.0,1.21.0,1.99.0,1.20.0"}
+	app := NewApp()
+	require.True(t, app.isBatchInstall())
+
+	// the version that no mirror has fails without stopping the others
+	out, err := captureOutput(t, func() error { return run(app) })
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &internal.ErrBadVersion{})
+	// the installs report on stderr so that stdout holds only the records
+	var records []installRecord
+	require.NoError(t, json.Unmarshal([]byte(out), &records), out)
+	require.Len(t, records, 3)
+	assert.Equal(t, installFailed, records[2].Status)
+	for _, record := range records[:2] {
+		assert.Equal(t, installInstalled, record.Status, record)
+	}
+	for _, version := range []string{"1.20.0", "1.21.0"} {
+		assert.FileExists(t, filepath.Join(workspace, "versions", version, "installer.lock"))
+		assertUnlocked(t, versionLockPath(workspace, version))
+	}
+	assertUnlocked(t, workspaceLockPath(workspace))
+	// the last listed version is activated whichever install finished last
+	active, err := app.activatedVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.21.0", active)
+
+	// installing them again only reports that they are installed
+	os.Args = []string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64", "-extras=false",
+		"-profiles=false", "-mirrors", mirrors, "-format", formatPlain, "-i", "1.21.0,1.20.0"}
+	app = NewApp()
+	out, err = captureOutput(t, func() error { return run(app) })
+	require.NoError(t, err)
+	assert.Contains(t, out, "1.21.0\t1.21.0\t"+installSkipped)
+	assert.Contains(t, out, "1.20.0\t1.20.0\t"+installSkipped)
+	active, err = app.activatedVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.21.0", active)
+}
+
+// fileMirror is offlineMirrors with the linux/amd64 tarball of each version and its
+// checksum file, it returns -mirrors and the checksum of each version
+func fileMirror(t *testing.T, versions ...string) (string, map[string]string) {
+	mirrors := offlineMirrors(t)
+	dir := strings.TrimPrefix(mirrors, "file://")
+	sums := map[string]string{}
+	for _, version := range versions {
+		name := "go" + version + ".linux-amd64.tar.gz"
+		sums[version] = createGoTarGz(t, filepath.Join(dir, name), "go version go"+version+" linux/amd64")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".sha256"), []byte(sums[version]+"  "+name+"\n"), 0644))
+	}
+	return mirrors, sums
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/andreimerlescu/igo/internal"
 	"github.com/fatih/color"
@@ -15,7 +20,7 @@ import (
 )
 
 // fix fixes the go version
-func fix(app *Application, version string) {
+func fix(app *Application, version string) error {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
 	onlyVerbose := verbose && !debug
 
@@ -28,31 +33,21 @@ func fix(app *Application, version string) {
 	workspace := app.Workspace()
 	symlinks, err := internal.FindSymlinks(workspace)
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
-		}
-		return
+		return err
 	}
 	if len(symlinks) == 0 {
-		color.Red("no go versions installed.")
-		return
+		return internal.ErrNoGoInstalled{Workspace: workspace}
 	}
 	activeVersion, err := app.activatedVersion()
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
-		}
-		return
+		return err
 	}
 	if debug || onlyVerbose {
 		color.Green("Active Go version: %v", activeVersion)
 	}
 	files, err := os.ReadDir(workspace)
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
-		}
-		return
+		return internal.ErrDirEntries{Path: workspace, Err: err}
 	}
 	results := map[string]string{
 		GOBIN:      "",
@@ -75,7 +70,7 @@ func fix(app *Application, version string) {
 			results["version"] = filepath.Join(workspace, dirEntry.Name())
 		}
 		if dirEntry.Name() == "versions" {
-			results[GOMODCACHE] = filepath.Join(workspace, "versions", version, "pkg", "mod")
+			results[GOMODCACHE] = filepath.Join(workspace, "versions", version, "go", "pkg", "mod")
 		}
 	}
 	for _, dirEntry := range files {
@@ -94,14 +89,11 @@ func fix(app *Application, version string) {
 			color.Red("!!! MISSING %s...", name)
 			switch name {
 			case GOPATH:
-				src := filepath.Join(workspace, "versions", version, "pkg", "mod")
+				src := filepath.Join(workspace, "versions", version)
 				tar := filepath.Join(workspace, "path")
 				err := os.Symlink(src, tar)
 				if err != nil {
-					if debug || onlyVerbose {
-						color.Red(err.Error())
-					}
-					return
+					return err
 				}
 				color.Green(CreatedSymlinkFmt, src, tar)
 				patched = true
@@ -110,10 +102,7 @@ func fix(app *Application, version string) {
 				tar := filepath.Join(workspace, "root")
 				err := os.Symlink(src, tar)
 				if err != nil {
-					if debug || onlyVerbose {
-						color.Red(err.Error())
-					}
-					return
+					return err
 				}
 				color.Green(CreatedSymlinkFmt, src, tar)
 				patched = true
@@ -122,10 +111,7 @@ func fix(app *Application, version string) {
 				tar := filepath.Join(workspace, "bin")
 				err := os.Symlink(src, tar)
 				if err != nil {
-					if debug || onlyVerbose {
-						color.Red(err.Error())
-					}
-					return
+					return err
 				}
 				color.Green(CreatedSymlinkFmt, src, tar)
 				patched = true
@@ -137,149 +123,168 @@ func fix(app *Application, version string) {
 	} else {
 		color.Green("Nothing to fix!")
 	}
+	return nil
 }
 
-// env prints the environment variables for the current go version
-func env(app *Application) {
+// doctor audits every invariant of the workspace and, with -repair, fixes the
+// findings that it knows how to repair
+func doctor(app *Application) error {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
-	onlyVerbose := verbose && !debug
 	if verbose {
-		color.Red(VerboseEnabled)
+		color.Green(VerboseEnabled)
 	}
 	if debug {
 		color.Red(DebugEnabled)
 	}
 	workspace := app.Workspace()
-	_, dirErr := os.Stat(workspace)
-	if os.IsNotExist(dirErr) {
-		color.Red(NoGoMessage)
-		return
+	if _, err := os.Stat(workspace); os.IsNotExist(err) {
+		return internal.ErrNoGoInstalled{Workspace: workspace}
 	}
-	currentVersion, err := app.activatedVersion()
+	format, err := app.outputFormat()
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
-		}
-		if !os.IsNotExist(err) {
-			return
-		}
+		return err
 	}
-	have := map[string]bool{
-		"GOBIN":      false,
-		"GOPATH":     false,
-		"GOMODCACHE": false,
-		"GOROOT":     false,
+	repair := *app.Figs.Bool(kRepair)
+	if repair {
+		lock, err := lockFile(workspaceLockPath(workspace), *app.Figs.Duration(kLockTimeout))
+		if err != nil {
+			return err
+		}
+		defer func() { internal.Discard(lock.unlock()) }()
 	}
-	color.Green("Current version: %v", currentVersion)
-	color.Green("│   ENV:")
-	env := os.Environ()
-	slices.Sort(env)
-	for _, val := range env {
-		parts := strings.SplitN(val, "=", 2)
-		if len(parts) != 2 || !strings.HasPrefix(parts[0], "GO") {
+	findings := app.diagnose()
+	var repairErrs []error
+	for i := range findings {
+		if !repair || findings[i].repair == nil {
 			continue
 		}
-		color.Green("│   ├── %v=%v", parts[0], parts[1])
-		if _, exists := have[parts[0]]; exists {
-			have[parts[0]] = true
+		if err := findings[i].repair(); err != nil {
+			repairErrs = append(repairErrs, fmt.Errorf("failed to repair %s: %w", findings[i].Path, err))
+			continue
 		}
+		findings[i].Repaired = true
 	}
-	var (
-		binDir  = filepath.Join(workspace, "bin")
-		pathDir = filepath.Join(workspace, "path")
-		rootDir = filepath.Join(workspace, "root")
-		shimDir = filepath.Join(workspace, "shims")
-		modDir  = filepath.Join(workspace, "versions", currentVersion, "pkg", "mod")
-	)
-	path := os.Getenv("PATH")
-	path = strings.TrimSpace(path)
-	requiredPaths := map[string]bool{shimDir: false, binDir: false}
-	paths := strings.Split(path, string(filepath.ListSeparator))
-	pathVersions := make(map[string]bool)
-	for _, p := range paths {
-		if _, exists := requiredPaths[p]; exists {
-			requiredPaths[p] = true
-		}
-		if strings.HasPrefix(p, filepath.Join(workspace, "versions")) {
-			pathVersions[p] = true
-		}
-	}
-	// writePaths := slices.Clone(paths)
-	writePaths := make(map[string]bool)
-	for _, p := range paths {
-		if _, exists := requiredPaths[p]; exists {
-			writePaths[p] = true
-		}
-	}
-	for p, _ := range pathVersions {
-		v := strings.TrimPrefix(p, filepath.Join(workspace, "versions"))
-		v = strings.TrimSuffix(v, filepath.Join("go", "bin"))
-		v = strings.ReplaceAll(v, string(filepath.Separator), "")
-		if v != currentVersion {
-			for wp, _ := range writePaths {
-				if strings.HasPrefix(wp, p) {
-					delete(writePaths, wp)
-				}
-			}
+	problems := 0
+	for _, f := range findings {
+		if f.Severity == severityError && !f.Repaired {
+			problems++
 		}
 	}
-	for k, v := range have {
-		if !v {
-			switch k {
-			case "GOBIN":
-				internal.Capture(os.Setenv(k, binDir))
-				color.Green(IndentList, k, binDir)
-			case "GOPATH":
-				internal.Capture(os.Setenv(k, pathDir))
-				color.Green(IndentList, k, pathDir)
-			case "GOMODCACHE":
-				internal.Capture(os.Setenv(k, modDir))
-				color.Green(IndentList, k, modDir)
-			case "GOROOT":
-				internal.Capture(os.Setenv(k, rootDir))
-				color.Green(IndentList, k, rootDir)
+	switch format {
+	case formatJSON, formatYAML:
+		if findings == nil {
+			findings = []finding{}
+		}
+		if err := writeRecords(os.Stdout, format, findings); err != nil {
+			return err
+		}
+	case formatPlain:
+		for _, f := range findings {
+			fmt.Printf("%s\t%s\t%s\t%s\t%t\n", f.Severity, f.Check, f.Path, f.Message, f.Repaired)
+		}
+	default:
+		for _, f := range findings {
+			switch {
+			case f.Repaired:
+				color.Green("REPAIRED %s: %s", f.Check, f.Message)
+			case f.Severity == severityError:
+				color.Red("ERROR    %s: %s", f.Check, f.Message)
+			case f.Severity == severityWarning:
+				color.Yellow("WARNING  %s: %s", f.Check, f.Message)
+			default:
+				color.Blue("INFO     %s: %s", f.Check, f.Message)
 			}
 		}
-	}
-	color.Green("│   PATH: ")
-	for k, v := range requiredPaths {
-		if !v {
-			writePaths[k] = true
+		if len(findings) == 0 {
+			color.Green("No problems found in %s", workspace)
+		} else if !repair && slices.ContainsFunc(findings, func(f finding) bool { return f.repair != nil }) {
+			color.Yellow("Run igo -%s -%s to repair what can be repaired", cmdDoctor, kRepair)
 		}
 	}
-	newPaths := make([]string, 0, len(writePaths))
-	for p, _ := range writePaths {
-		color.Green(IndentItem, p)
-		newPaths = append(newPaths, p)
+	if len(repairErrs) > 0 {
+		return errors.Join(repairErrs...)
+	}
+	if problems > 0 {
+		return internal.ErrUnhealthy{Workspace: workspace, Problems: problems}
 	}
-	slices.Sort(newPaths)
-	internal.Capture(os.Setenv("PATH", strings.Join(newPaths, string(filepath.ListSeparator))))
-	links, err := internal.FindSymlinks(workspace)
-	slices.Sort(links)
+	return nil
+}
+
+// showVersion prints the version of the igo binary
+func showVersion(format string) error {
+	record := binaryRecord{Version: BinaryVersion(), About: internal.About()}
+	switch format {
+	case formatJSON, formatYAML:
+		return writeRecords(os.Stdout, format, record)
+	case formatPlain:
+		fmt.Println(record.Version)
+		return nil
+	}
+	color.Magenta(record.Version + " - " + record.About)
+	return nil
+}
+
+// env prints the environment variables for the current go version
+func env(app *Application) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Red(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	workspace := app.Workspace()
+	_, dirErr := os.Stat(workspace)
+	if os.IsNotExist(dirErr) {
+		return internal.ErrNoGoInstalled{Workspace: workspace}
+	}
+	format, err := app.outputFormat()
+	if err != nil {
+		return err
+	}
+	record, err := app.envRecord()
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
+		return err
+	}
+	names := make([]string, 0, len(record.Env))
+	for name := range record.Env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	switch format {
+	case formatJSON, formatYAML:
+		return writeRecords(os.Stdout, format, record)
+	case formatPlain:
+		for _, name := range names {
+			fmt.Printf("env\t%s\t%s\n", name, record.Env[name])
 		}
-		return
+		for _, path := range record.Path {
+			fmt.Printf("path\t%s\n", path)
+		}
+		for _, link := range record.Links {
+			fmt.Printf("link\t%s\t%s\t%t\n", link.Link, link.Target, link.Verified && link.Exists)
+		}
+		return nil
+	}
+	color.Green("Current version: %v", record.Version)
+	color.Green("│   ENV:")
+	for _, name := range names {
+		color.Green(IndentList, name, record.Env[name])
+	}
+	color.Green("│   PATH: ")
+	for _, path := range record.Path {
+		color.Green(IndentItem, path)
 	}
 	color.Green("└── LINKS:")
-	for _, link := range links {
-		to, err := internal.ReadSymlink(link)
-		if err != nil {
-			if debug || onlyVerbose {
-				color.Red(err.Error())
-			}
-			continue
-		}
-		color.Green(IndentValue, link, to, internal.VerifyLink(link, to))
+	for _, link := range record.Links {
+		color.Green(IndentValue, link.Link, link.Target, internal.VerifyLink(link.Link, link.Target))
 	}
-	return
+	return nil
 }
 
 // uninstall removes a version of go.
-func uninstall(app *Application, version string) {
+func uninstall(app *Application, version string) error {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
-	onlyVerbose := verbose && !debug
 	if verbose {
 		color.Green(VerboseEnabled)
 	}
@@ -290,36 +295,64 @@ func uninstall(app *Application, version string) {
 	workspace := app.Workspace()
 	_, dirErr := os.Stat(workspace)
 	if os.IsNotExist(dirErr) {
-		color.Red(NoGoMessage)
-		return
+		return internal.ErrNoGoInstalled{Workspace: workspace}
 	}
+	if err := removeVersion(app, version); err != nil {
+		return err
+	}
+	color.Green("Uninstalled version: %s", version)
+	return nil
+}
+
+// removeVersion deletes versions/<version> and, when it is the activated version, the
+// symlinks and version file of the workspace
+func removeVersion(app *Application, version string) error {
+	workspace := app.Workspace()
 	currentVersion, err := app.activatedVersion()
-	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	versionDir := filepath.Join(workspace, "versions", version)
+	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
+		return internal.ErrNotInstalled{Version: version}
+	}
+	// wait for installs of this version and changes to the workspace symlinks to finish
+	timeout := *app.Figs.Duration(kLockTimeout)
+	for _, path := range []string{versionLockPath(workspace, version), workspaceLockPath(workspace)} {
+		lock, err := lockFile(path, timeout)
+		if err != nil {
+			return err
 		}
-		return
+		defer func() { internal.Discard(lock.unlock()) }()
 	}
 	binDir := filepath.Join(workspace, "bin")
 	pathDir := filepath.Join(workspace, "path")
 	rootDir := filepath.Join(workspace, "root")
-	versionDir := filepath.Join(workspace, "versions", version)
 	versionFile := filepath.Join(workspace, "version")
-	internal.Capture(internal.RemoveStickyBit(versionDir))
-	internal.Capture(internal.RemoveSetuidSetgidBits(versionDir))
-	if strings.Contains(currentVersion, version) {
+	if err := internal.RemoveStickyBit(versionDir); err != nil {
+		return err
+	}
+	if err := internal.RemoveSetuidSetgidBits(versionDir); err != nil {
+		return err
+	}
+	if currentVersion == version {
 		for _, path := range []string{binDir, pathDir, rootDir} {
-			internal.Capture(os.RemoveAll(path))
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		if err := os.Remove(versionFile); err != nil && !os.IsNotExist(err) {
+			return err
 		}
-		internal.Capture(os.Remove(versionFile))
 	}
-	internal.Capture(internal.MakeDirsWritable(versionDir))
-	internal.Capture(os.RemoveAll(versionDir))
-	color.Green("Uninstalled version: %s", version)
+	if err := internal.MakeDirsWritable(versionDir); err != nil {
+		return err
+	}
+	return os.RemoveAll(versionDir)
 }
 
 // use sets the version of go to use.
-func use(app *Application, version string) {
+func use(app *Application, version string) error {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
 	onlyVerbose := verbose && !debug
 	if verbose {
@@ -331,19 +364,15 @@ func use(app *Application, version string) {
 	workspace := app.Workspace()
 	_, dirErr := os.Stat(workspace)
 	if os.IsNotExist(dirErr) {
-		color.Red(NoGoMessage)
-		return
+		return internal.ErrNoGoInstalled{Workspace: workspace}
 	}
 	currentVersion, err := app.activatedVersion()
-	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
-		}
-		return
+	if err != nil && !os.IsNotExist(err) {
+		return err
 	}
-	if strings.Contains(currentVersion, version) {
+	if currentVersion == version {
 		color.Green("Already using version %v", currentVersion)
-		return
+		return nil
 	}
 	var (
 		binDir       = filepath.Join(workspace, "bin")
@@ -372,10 +401,7 @@ func use(app *Application, version string) {
 	}
 	_, err = os.Stat(versionDir)
 	if os.IsNotExist(err) {
-		if debug || onlyVerbose {
-			color.Red(NoGoMessage)
-		}
-		return
+		return internal.ErrNotInstalled{Version: version}
 	}
 	var paths = map[string]string{
 		filepath.Join(versionDir):              pathDir,
@@ -388,49 +414,35 @@ func use(app *Application, version string) {
 		}
 		err = internal.RemoveSymlinkOrBackupPath(target)
 		if err != nil {
-			if debug || onlyVerbose {
-				color.Red("Failed to link %v -> %v due to err: %s", target, source, err)
-			}
-			return
+			return err
 		}
 		if err := os.Symlink(source, target); err != nil {
-			if debug || verbose {
-				color.Red("Failed to link %v -> %v due to err: %s", source, target, err)
-			}
-			return
+			return err
 		}
 	}
 	// replace VERSION file of go
-	err = os.Remove(versionFile)
-	if err != nil {
-		if debug || onlyVerbose {
-			color.Red("Failed to remove version file due to err: %s", err)
-		}
-		return
-	}
 	err = os.WriteFile(versionFile, []byte(version), 0644)
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red("Failed to write version file due to err: %s", err)
-		}
-		return
+		return internal.ErrFile{Path: versionFile, Err: err, How: "os.WriteFile"}
 	}
 	if debug {
-		versionFound := app.runVersionCheck(envs, version)
+		versionFound, err := app.runVersionCheck(envs, version)
+		if err != nil {
+			return err
+		}
 		if !strings.Contains(versionFound, version) {
-			color.Red("Mismatched go version %v and found %v", version, versionFound)
-			return
+			return internal.ErrVersionMismatch{Want: version, Got: versionFound}
 		}
 	}
 	if debug || onlyVerbose {
 		color.Green("Set go version %v", version)
 	}
+	return nil
 }
 
 // list lists all installed go versions
-func list(app *Application) {
+func list(app *Application) error {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
-	onlyVerbose, onlyDebug := verbose && !debug, !verbose && debug
 	if verbose {
 		color.Green(VerboseEnabled)
 	}
@@ -440,42 +452,66 @@ func list(app *Application) {
 	workspace := app.Workspace()
 	_, dirErr := os.Stat(workspace)
 	if os.IsNotExist(dirErr) {
-		color.Red(NoGoMessage)
-		return
+		return internal.ErrNoGoInstalled{Workspace: workspace}
 	}
-	versions, err := app.findGoVersions()
+	format, err := app.outputFormat()
 	if err != nil {
-		if onlyVerbose {
-			color.Red(err.Error())
-		}
-		if debug {
-			color.Red(err.Error())
+		return err
+	}
+	records, err := app.versionRecords(format != formatTable)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case formatJSON, formatYAML:
+		return writeRecords(os.Stdout, format, records)
+	case formatPlain:
+		for _, record := range records {
+			lastUsed := "-"
+			if record.LastUsed != nil {
+				lastUsed = record.LastUsed.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%d\t%s\t%t\t%s\t%s\n", record.Version, record.Path, record.Size,
+				record.Created.Format(time.RFC3339), record.Active, lastUsed, record.LastUsedDir)
 		}
-		return
+		return nil
 	}
-	slices.Sort(versions)
-	slices.Reverse(versions)
-	currentVersion, _ := app.activatedVersion()
 	var data [][]string
-	for _, version := range versions {
-		info, infoErr := os.Stat(filepath.Join(workspace, "versions", version))
-		if os.IsNotExist(infoErr) {
-			if onlyDebug {
-				return
-			}
-			continue
+	for _, record := range records {
+		var status []string
+		if record.Active {
+			status = append(status, "* ACTIVE")
+		}
+		if record.PreRelease {
+			status = append(status, "PRE-RELEASE")
 		}
 		a := ""
-		if strings.EqualFold(version, currentVersion) {
-			a = " * ACTIVE "
+		if len(status) > 0 {
+			a = " " + strings.Join(status, " ") + " "
+		}
+		lastUsed := "never"
+		if record.LastUsed != nil {
+			lastUsed = fmt.Sprintf("%s in %s", record.LastUsed.Local().Format("2006-01-02 15:04"), record.LastUsedDir)
 		}
 		data = append(data, []string{
-			version,
-			info.ModTime().Format("2006-01-02 15:04"),
+			record.Version,
+			record.Created.Format("2006-01-02 15:04"),
+			lastUsed,
 			a,
 		})
 	}
 	color.Magenta(internal.About())
+	table := newVersionTable()
+	table.Header([]string{"Version", "Installed", "Last Used", "Status"})
+	table.Footer([]string{"I ❤ YOU!", "Made In America", "", "Be Inspired"})
+	if err := table.Bulk(data); err != nil {
+		return err
+	}
+	return table.Render()
+}
+
+// newVersionTable returns the decorated table used to render lists of go versions
+func newVersionTable() *tablewriter.Table {
 	symbols := tw.NewSymbolCustom("Nature").
 		WithRow("~").
 		WithColumn("|").
@@ -495,6 +531,7 @@ func list(app *Application) {
 				{FG: renderer.Colors{color.FgHiRed, color.Bold}},
 				{FG: renderer.Colors{color.FgHiWhite, color.Bold}},
 				{FG: renderer.Colors{color.FgHiBlue, color.Bold}},
+				{FG: renderer.Colors{color.FgHiCyan, color.Bold}},
 			},
 			BG: renderer.Colors{color.BgHiWhite},
 		},
@@ -504,6 +541,7 @@ func list(app *Application) {
 				{FG: renderer.Colors{color.FgHiRed}},
 				{FG: renderer.Colors{color.FgHiWhite}},
 				{FG: renderer.Colors{color.FgHiBlue}},
+				{FG: renderer.Colors{color.FgHiCyan}},
 			},
 		},
 		Footer: renderer.Tint{
@@ -517,7 +555,7 @@ func list(app *Application) {
 		Border:    renderer.Tint{FG: renderer.Colors{color.FgWhite}},
 		Separator: renderer.Tint{FG: renderer.Colors{color.FgWhite}},
 	}
-	table := tablewriter.NewTable(os.Stdout,
+	return tablewriter.NewTable(os.Stdout,
 		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{Symbols: symbols})),
 		tablewriter.WithRenderer(renderer.NewColorized(colorCfg)),
 		tablewriter.WithConfig(tablewriter.Config{
@@ -532,26 +570,136 @@ func list(app *Application) {
 			},
 		}),
 	)
-	table.Header([]string{"Version", "Creation", "Status"})
+}
+
+// remote lists the versions of go available on go.dev for -goos and -goarch
+func remote(app *Application) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	onlyVerbose := verbose && !debug
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	releases, err := app.fetchReleases()
+	if err != nil {
+		return err
+	}
+	security, err := app.fetchSecurityReleases()
+	if err != nil && (debug || onlyVerbose) {
+		color.Red(err.Error())
+	}
+	installed, _ := app.findGoVersions()
+	currentVersion, _ := app.activatedVersion()
+	goos, goarch := *app.Figs.String(kGoos), *app.Figs.String(kGoArch)
+	available := availableReleases(releases, goos, goarch)
+	supported := supportedMinors(available)
+	var data [][]string
+	for _, v := range available {
+		var status []string
+		if slices.Contains(installed, v.Raw) {
+			status = append(status, "INSTALLED")
+		}
+		if v.Raw == currentVersion {
+			status = append(status, "* ACTIVE")
+		}
+		var notes []string
+		if v.IsPreRelease() {
+			notes = append(notes, "PRE-RELEASE")
+		} else if supported[[2]int{v.Major, v.Minor}] {
+			notes = append(notes, "SUPPORTED")
+		}
+		if security[v.Raw] {
+			notes = append(notes, "SECURITY")
+		}
+		data = append(data, []string{v.Raw, strings.Join(status, " "), strings.Join(notes, " ")})
+	}
+	color.Magenta(internal.About())
+	color.Green("Available versions of Go for %s/%s", goos, goarch)
+	table := newVersionTable()
+	table.Header([]string{"Version", "Status", "Release"})
 	table.Footer([]string{"I ❤ YOU!", "Made In America", "Be Inspired"})
-	err = table.Bulk(data)
+	if err := table.Bulk(data); err != nil {
+		return err
+	}
+	return table.Render()
+}
+
+// execute runs args with the environment of an installed version of go without
+// changing the activated version of the Workspace()
+func execute(app *Application, selector string, args []string) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	if len(args) == 0 {
+		return internal.ErrUsage{Usage: fmt.Sprintf("igo -%s <version> -- <command> [args...]", cmdExec)}
+	}
+	version, err := app.resolveSelector(cmdExec, selector)
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
+		return internal.ErrBadVersion{Version: selector, Err: err}
+	}
+	versionDir := filepath.Join(app.Workspace(), "versions", version)
+	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
+		return internal.ErrNotInstalled{Version: version}
+	}
+	envs := app.execEnvs(version)
+	if debug {
+		for name, value := range envs {
+			color.Green("   %s=%s", name, value)
 		}
-		return
 	}
-	err = table.Render()
+	environ := mergeEnviron(os.Environ(), envs)
+	if err := os.Setenv("PATH", envs["PATH"]); err != nil {
+		return err
+	}
+	binary, err := exec.LookPath(args[0])
 	if err != nil {
-		if debug || onlyVerbose {
-			color.Red(err.Error())
+		return internal.ErrCommandNotFound{Name: args[0], Err: err}
+	}
+	if verbose {
+		color.Green("Executing %s with go %s", binary, version)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		_ = recordUsage(app.Workspace(), version, cwd, time.Now())
+	}
+	return syscall.Exec(binary, args, environ)
+}
+
+// execEnvs returns the variables that -exec sets for version, which replace the ones
+// already exported since a program reads the first of two entries with the same name
+func (app *Application) execEnvs(version string) map[string]string {
+	envs := app.versionEnvs(version)
+	// the shims read IGO_VERSION first so go and gofmt resolve to this version
+	envs[envVersion] = version
+	envs["PATH"] = strings.Join([]string{envs[GOSHIMS], envs[GOBIN], os.Getenv("PATH")}, string(filepath.ListSeparator))
+	return envs
+}
+
+// mergeEnviron returns environ with the variables of envs replaced or added, a
+// program reads the first entry of a variable that appears twice so appending envs
+// would leave GOROOT=<godir>/root from the shell profiles in charge
+func mergeEnviron(environ []string, envs map[string]string) []string {
+	merged := make([]string, 0, len(environ)+len(envs))
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if _, ok := envs[name]; !ok {
+			merged = append(merged, entry)
 		}
-		return
 	}
+	for name, value := range envs {
+		merged = append(merged, name+"="+value)
+	}
+	return merged
 }
 
-// install installs a go version
-func install(app *Application, version string) {
+// install stages version into the workspace as a transaction that is rolled back
+// when any step fails, only committing once the install is complete
+func install(app *Application, version string) (err error) {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
 	// onlyVerbose, onlyDebug := verbose && !debug, !verbose && debug
 	if verbose {
@@ -566,7 +714,9 @@ func install(app *Application, version string) {
 	}
 	_, workspaceErr := os.Stat(workspace)
 	if os.IsNotExist(workspaceErr) {
-		internal.Capture(os.MkdirAll(workspace, 0755))
+		if err := os.MkdirAll(workspace, 0755); err != nil {
+			return err
+		}
 		if verbose {
 			color.Green("Create workspace directory: %v", workspace)
 		}
@@ -584,7 +734,9 @@ func install(app *Application, version string) {
 	)
 	_, shimsErr := os.Stat(shimDir)
 	if os.IsNotExist(shimsErr) {
-		internal.Capture(os.MkdirAll(shimDir, 0755))
+		if err := os.MkdirAll(shimDir, 0755); err != nil {
+			return err
+		}
 		if verbose {
 			color.Green("Create shim directory: %v", shimDir)
 		}
@@ -602,7 +754,7 @@ func install(app *Application, version string) {
 		GOTELEMETRYDIR: telemetryDir,
 		GOCACHE:        cacheDir,
 	}
-	installerLockFile := filepath.Join(workspace, "installer.lock")
+	installerLockFile := workspaceLockPath(workspace)
 	versionLockFile := filepath.Join(versionDir, "installer.lock")
 	tarball := fmt.Sprintf("go%s.%s-%s.tar.gz", version, envs[GOOS], envs[GOARCH])
 	downloadsDir := filepath.Join(workspace, "downloads")
@@ -613,172 +765,267 @@ func install(app *Application, version string) {
 		DownloadName: tarball,
 		TarPath:      filepath.Join(downloadsDir, tarball),
 		ExtractPath:  filepath.Join(versionsDir, version),
+		SHA256:       cmp.Or(*app.Figs.String(kSHA256), app.checksums[tarball]),
+	}
+	localTarball := *app.Figs.String(kTarball)
+	timeout := *app.Figs.Duration(kLockTimeout)
+	// the version lock lets different versions install at the same time while another
+	// install of the same version, like a shim in a parallel CI job, waits for this one
+	versionLock, err := lockFile(versionLockPath(workspace, version), timeout)
+	if err != nil {
+		return err
 	}
-	// this file protects the runtime of the igo install func - when its present, the script aborts
-	_, err := os.Stat(installerLockFile) // check igo runtime installer.lock
+	defer func() { internal.Discard(versionLock.unlock()) }()
+	if verbose {
+		color.Green("Locked %s", versionLock.path)
+	}
+	// versions/<version>/installer.lock marks a finished install
+	if _, statErr := os.Stat(versionLockFile); statErr == nil {
+		color.Green("Go %s is already installed", version)
+		return nil
+	}
+	// the workspace lock is taken before the symlinks are changed and released after a rollback
+	var workspaceLock *fileLock
+	defer func() { internal.Discard(workspaceLock.unlock()) }()
+	// every change to the workspace and shell profiles from here on is recorded in tx
+	tx := &transaction{}
 	defer func() {
-		_, err = os.Stat(installerLockFile)
-		if os.IsNotExist(err) {
+		if err == nil {
 			return
 		}
-		if os.IsPermission(err) {
-			internal.Capture(err)
-		}
-		err = os.Remove(installerLockFile)
-		if err != nil {
-			internal.Capture(err)
+		color.Yellow("Rolling back the install of go %s: %v", version, err)
+		if rollbackErr := tx.rollback(); rollbackErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to roll back: %w", rollbackErr))
 		}
 	}()
-	if os.IsExist(err) { // installer.lock exists
-		return
-	}
-	_, err = os.Stat(versionLockFile)
-	if os.IsExist(err) {
-		return
-	}
-	// write the current version to the lockFile
-	internal.Capture(os.WriteFile(installerLockFile, []byte(version), 0644))
-	if verbose {
-		color.Green("Created igo lockfile at %v", installerLockFile)
-	}
-	// create the downloads directory
-	_, err = os.Stat(downloadsDir)
-	if os.IsNotExist(err) {
-		internal.Capture(os.MkdirAll(downloadsDir, 0755))
+	// create the downloads directory, which is a cache that is kept when rolling back
+	_, statErr := os.Stat(downloadsDir)
+	if os.IsNotExist(statErr) {
+		if err := os.MkdirAll(downloadsDir, 0755); err != nil {
+			return err
+		}
 		if verbose {
 			color.Green("Created directory %s", downloadsDir)
 		}
 	}
+	// copy the -tarball into the downloads directory so a bad checksum never removes it
+	if len(localTarball) > 0 {
+		if err := versionData.copyFrom(localTarball); err != nil {
+			return err
+		}
+		if verbose {
+			color.Green("Copied %s to %s", localTarball, versionData.TarPath)
+		}
+	}
 	// check if the download exists
 	_, tarErr := os.Stat(filepath.Join(downloadsDir, tarball))
 	if os.IsNotExist(tarErr) {
 		// download the tar.gz
-		internal.Capture(versionData.downloadURL(app))
+		if err := versionData.downloadURL(app); err != nil {
+			return err
+		}
 		if verbose {
 			color.Green("Download file %s to %s", tarball, downloadsDir)
 		}
 	}
-	// create if not exists the version extract destination
-	_, err = os.Stat(versionData.ExtractPath)
-	if os.IsNotExist(err) {
-		internal.Capture(os.MkdirAll(versionData.ExtractPath, 0755))
-		if verbose {
-			color.Green("Created directory %s", versionData.ExtractPath)
+	// verify the tar.gz against the go.dev checksum before extracting it
+	verifyErr := versionData.verifyChecksum(app)
+	var mismatch internal.ErrChecksumMismatch
+	if errors.As(verifyErr, &mismatch) && !mismatch.Pinned && tarErr == nil && len(localTarball) == 0 {
+		// the cached tarball was corrupt and has been removed, download it again
+		color.Yellow("Cached %s failed verification, downloading again", tarball)
+		if err := versionData.downloadURL(app); err != nil {
+			return err
 		}
+		verifyErr = versionData.verifyChecksum(app)
+	}
+	if verifyErr != nil {
+		return verifyErr
+	}
+	// extract the tar.gz into a staging directory next to the version destination
+	if err := tx.mkdirAll(versionsDir, 0755); err != nil {
+		return err
+	}
+	stagingDir, err := os.MkdirTemp(versionsDir, "."+version+".staging-")
+	if err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return os.RemoveAll(stagingDir)
+	})
+	versionData.ExtractPath = stagingDir
+	if verbose {
+		color.Green("Created staging directory %s", stagingDir)
+	}
+	if err := versionData.extractTarGz(app); err != nil {
+		return err
 	}
-	// extract the tar.gz into the destination
-	internal.Capture(versionData.extractTarGz(app))
 	if verbose {
 		color.Green("Extracted %s to %s", versionData.DownloadName, versionData.ExtractPath)
 	}
 	// move go to go.version in the version dir
-	o := filepath.Join(versionDir, "go", "bin", "go")
-	n := filepath.Join(versionDir, "go", "bin", "go."+version)
-	internal.Capture(os.Rename(o, n))
+	o := filepath.Join(stagingDir, "go", "bin", "go")
+	n := filepath.Join(stagingDir, "go", "bin", "go."+version)
+	if err := os.Rename(o, n); err != nil {
+		return err
+	}
 	if verbose {
 		color.Green("Renamed %s to %s", o, n)
 	}
 	// move gofmt to gofmt.version in the version dir
-	o = filepath.Join(versionDir, "go", "bin", "gofmt")
-	n = filepath.Join(versionDir, "go", "bin", "gofmt."+version)
-	internal.Capture(os.Rename(o, n))
+	o = filepath.Join(stagingDir, "go", "bin", "gofmt")
+	n = filepath.Join(stagingDir, "go", "bin", "gofmt."+version)
+	if err := os.Rename(o, n); err != nil {
+		return err
+	}
 	if verbose {
 		color.Green("Renamed %s to %s", o, n)
 	}
-	// create a symlink in version dir to shim go
-	err = app.CreateShims()
+	// move a leftover unlocked version dir aside and move the staged version in its place
+	if err := tx.moveAside(versionDir); err != nil {
+		return err
+	}
+	if err := tx.rename(stagingDir, versionDir); err != nil {
+		return err
+	}
+	versionData.ExtractPath = versionDir
+	if verbose {
+		color.Green("Moved %s to %s", stagingDir, versionDir)
+	}
+	// the symlinks, version file and shell profiles are shared by every version
+	workspaceLock, err = lockFile(installerLockFile, timeout)
 	if err != nil {
-		return
+		return err
 	}
-	// if GOROOT is a directory, move it to root.bak in the app.Workspace()
-	internal.Capture(internal.RemoveSymlinkOrBackupPath(rootDir))
-	// symlink for GOROOT to version go directory
+	if verbose {
+		color.Green("Locked %s", workspaceLock.path)
+	}
+	// create a symlink in version dir to shim go
+	if err := app.CreateShims(); err != nil {
+		return err
+	}
+	// symlink for GOROOT to version go directory, if GOROOT is a directory it is moved to root.bak
 	src := filepath.Join(versionDir, "go")
 	tar := rootDir
-	internal.Capture(os.Symlink(src, tar))
+	if err := tx.link(src, tar); err != nil {
+		return err
+	}
 	if verbose {
 		color.Green(CreatedSymlinkFmt, src, tar)
 	}
-	// if GOBIN is a directory, move it to bin.bak in the app.Workspace()
-	internal.Capture(internal.RemoveSymlinkOrBackupPath(binDir))
-	// symlink for GOBIN to version go directory
+	// symlink for GOBIN to version go directory, if GOBIN is a directory it is moved to bin.bak
 	src = filepath.Join(versionDir, "go", "bin")
 	tar = binDir
-	internal.Capture(os.Symlink(src, tar))
+	if err := tx.link(src, tar); err != nil {
+		return err
+	}
 	if verbose {
 		color.Green(CreatedSymlinkFmt, src, tar)
 	}
-	internal.Capture(internal.RemoveSymlinkOrBackupPath(pathDir))
 	// symlink for GOPATH to version go directory
 	src = strings.Clone(versionDir)
 	tar = pathDir
-	internal.Capture(os.Symlink(src, tar))
+	if err := tx.link(src, tar); err != nil {
+		return err
+	}
 	if verbose {
 		color.Green(CreatedSymlinkFmt, src, tar)
 	}
-	// add GOBIN/GOROOT/GOOS/GOARCH/GOPATH to ~/.zshrc or ~/.bashrc
-	internal.Capture(app.injectEnvVarsToShellConfig(envs))
-	if verbose || debug {
-		color.Green("Patched igo variables in ENV")
-		for name, value := range envs {
-			color.Green("   %s=%s\n", name, value)
+	// the shell profiles are left alone once the user loads igo with -init
+	if *app.Figs.Bool(kProfiles) && !app.hasManagedBlock() {
+		// remember the shell configs before they are patched so they can be restored
+		for _, shellFile := range app.shellConfigFiles() {
+			if err := tx.snapshot(shellFile); err != nil {
+				return err
+			}
 		}
-	}
-	// update PATH in ~/.zshrc and ~/.bashrc to use GOSHIMS and GOBIN directories before PATH
-	internal.Capture(app.patchShellConfigPath(envs))
-	if verbose || debug {
-		color.Green("Patched PATH in shell configs!")
+		// add GOBIN/GOROOT/GOOS/GOARCH/GOPATH to ~/.zshrc or ~/.bashrc
+		if err := app.injectEnvVarsToShellConfig(envs); err != nil {
+			return err
+		}
+		if verbose || debug {
+			color.Green("Patched igo variables in ENV")
+			for name, value := range envs {
+				color.Green("   %s=%s\n", name, value)
+			}
+		}
+		// update PATH in ~/.zshrc and ~/.bashrc to use GOSHIMS and GOBIN directories before PATH
+		if err := app.patchShellConfigPath(envs); err != nil {
+			return err
+		}
+		if verbose || debug {
+			color.Green("Patched PATH in shell configs!")
+		}
+	} else if verbose {
+		color.Green("Skipped editing the shell profiles")
 	}
 	// read the text printed in the "go version" for this version
-	dataInVersionFile := app.runVersionCheck(envs, version)
+	dataInVersionFile, err := app.runVersionCheck(envs, version)
+	if err != nil {
+		return err
+	}
 	if verbose || debug {
 		color.Green("Found data in version file response: %v", dataInVersionFile)
 	}
 	// validate the format matches
 	if !strings.Contains(strings.TrimSpace(dataInVersionFile), version) {
-		e := fmt.Errorf("failed check - mismatched versions got = %s ; wanted = %s", dataInVersionFile, version)
-		if verbose || debug {
-			color.Red("Received Err: %v", e)
-		}
-		return
+		return internal.ErrVersionMismatch{Want: version, Got: dataInVersionFile}
 	}
 	if verbose {
 		color.Green("Verified that the correct version of Go was just installed and it works!")
 	}
-	// open the version file
+	// write the current version to the version file
 	versionFile := filepath.Join(workspace, "version")
-	fileHandler := internal.CaptureOpenFile(versionFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
-	if verbose {
-		color.Green("Opened the %v", versionFile)
+	if err := tx.writeFile(versionFile, []byte(version), 0644); err != nil {
+		return err
 	}
-	// write the current version
-	internal.CaptureInt(fileHandler.Write([]byte(version)))
 	if verbose {
 		color.Green("Wrote '%s' to %s", version, versionFile)
 	}
-	internal.Capture(os.MkdirAll(telemetryDir, 0755))
-	internal.Capture(os.MkdirAll(cacheDir, 0755))
+	if err := tx.mkdirAll(telemetryDir, 0755); err != nil {
+		return err
+	}
+	if err := tx.mkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
 	// report back to the user
 	if verbose {
 		color.Green("Assigned the igo version to %v", version)
 	}
-	// close the current version file handler
-	internal.Capture(fileHandler.Close())
-	// install extra packages on the system
-	internal.Capture(app.installExtraPackages(envs, version))
-	if verbose {
+	// install extra packages on the system, a failed package does not fail the install
+	if err := app.installExtraPackages(envs, version); err != nil {
+		color.Red(err.Error())
+	} else if verbose {
 		color.Green("Installed extra packages successfully!")
 	}
-	internal.Capture(internal.SetStickyBit(versionDir))
-	internal.Capture(internal.SetSetuidSetgidBits(versionDir))
+	// record what was installed for -export-lock
+	metadata := installMetadata{
+		Version:     version,
+		Tarball:     tarball,
+		SHA256:      versionData.SHA256,
+		GOOS:        envs[GOOS],
+		GOARCH:      envs[GOARCH],
+		InstalledAt: time.Now().UTC(),
+	}
+	if err := metadata.write(versionDir); err != nil {
+		return err
+	}
+	if err := internal.SetStickyBit(versionDir); err != nil {
+		return err
+	}
+	if err := internal.SetSetuidSetgidBits(versionDir); err != nil {
+		return err
+	}
 	// write a lockfile to the version directory to prevent future changes by this script
-	internal.Capture(internal.Touch(versionLockFile))
+	if err := internal.Touch(versionLockFile); err != nil {
+		return err
+	}
 	if verbose {
 		color.Green("Locked version of go with locker file at %v", versionLockFile)
 	}
-	// when we're finished, remove the installer.lock file
-	internal.Capture(os.Remove(installerLockFile))
-	if verbose {
-		color.Green("Removed the igo runtime locker at %v", installerLockFile)
+	// every step succeeded, keep the changes and clean up what was moved aside
+	if err := tx.commit(); err != nil {
+		color.Yellow("Installed go %s but failed to clean up: %v", version, err)
 	}
+	return nil
 }
@@ -45,21 +45,41 @@ const (
 	DebugEnabled      string = "DEBUG MODE ENABLED"
 	CreatedSymlinkFmt string = "Created symlink %s -> %s"
 	LinkingFmt        string = "Linking %v to %v"
-	NoGoMessage       string = "No installed version of Go found"
 	IndentList        string = "│   ├── %v=%s"
 	IndentItem        string = "│   ├── %v"
 	IndentValue       string = "    ├── %v -> %v %s  "
 	VersionFmt        string = "%d.%d.%d"
 
-	cmdInstall   string = "i" // mutagenesis = string (version)
-	cmdUninstall string = "u" // mutagenesis = string (version)
-	cmdActivate  string = "a" // mutagenesis = string (version)
-	cmdFix       string = "f" // mutagenesis = string (version) ; empty = current version activated
-	cmdList      string = "l" // mutagenesis = bool (true = display list)
-	cmdHelp      string = "h" // mutagenesis = bool (true = display help)
-	cmdVersion   string = "v" // mutagenesis = bool (true = display version)
-	cmdSwitch    string = "s" // mutagenesis = string (version)
-	cmdEnv       string = "e" // mutagenesis = bool (true = display env)
+	// envGoDir overrides the default -godir and the workspace that the shims resolve
+	envGoDir string = "IGO_GODIR"
+
+	// envVersion overrides the version of go that the shims resolve
+	envVersion string = "IGO_VERSION"
+
+	// envHookVersion is the version of go that the -init hook exported for the current directory
+	envHookVersion string = "IGO_HOOK_VERSION"
+
+	cmdInstall    string = "i"           // mutagenesis = string (version)
+	cmdUninstall  string = "u"           // mutagenesis = string (version)
+	cmdActivate   string = "a"           // mutagenesis = string (version)
+	cmdFix        string = "f"           // mutagenesis = string (version) ; empty = current version activated
+	cmdList       string = "l"           // mutagenesis = bool (true = display list)
+	cmdHelp       string = "h"           // mutagenesis = bool (true = display help)
+	cmdVersion    string = "v"           // mutagenesis = bool (true = display version)
+	cmdSwitch     string = "s"           // mutagenesis = string (version)
+	cmdEnv        string = "e"           // mutagenesis = bool (true = display env)
+	cmdRemote     string = "remote"      // mutagenesis = bool (true = display versions on go.dev)
+	cmdExec       string = "exec"        // mutagenesis = string (version) ; command follows --
+	cmdDoctor     string = "doctor"      // mutagenesis = bool (true = audit the workspace)
+	cmdPrune      string = "prune"       // mutagenesis = bool (true = remove what the prune policies select)
+	cmdInit       string = "init"        // mutagenesis = string (shell)
+	cmdHookEnv    string = "hook-env"    // mutagenesis = string (shell) ; evaluated by the -init hook
+	cmdPin        string = "pin"         // mutagenesis = string (version)
+	cmdWhich      string = "which"       // mutagenesis = bool (true = explain the version of a directory) ; directory follows
+	cmdCurrent    string = "current"     // mutagenesis = bool (alias to which)
+	cmdSync       string = "sync"        // mutagenesis = bool (true = sync the workspace to the manifest)
+	cmdExportLock string = "export-lock" // mutagenesis = string (file) ; - = stdout
+	cmdImportLock string = "import-lock" // mutagenesis = string (file)
 
 	// kGoDir defines -godir in the CLI to assign igoWorkspace()
 	kGoDir string = "godir"
@@ -81,11 +101,93 @@ const (
 	// without requiring you to set ENV variables first
 	kGoArch string = "goarch"
 
+	// kMirrors defines -mirrors in the CLI as the base URLs that tarballs are downloaded
+	// from in order, each being https://, http:// or a file:// directory
+	kMirrors string = "mirrors"
+
+	// kTarball defines -tarball in the CLI to install -i from a local tarball instead
+	// of downloading it
+	kTarball string = "tarball"
+
+	// kSHA256 defines -sha256 in the CLI as the expected checksum of the tarball when
+	// neither go.dev nor the -mirrors publish it
+	kSHA256 string = "sha256"
+
+	// kLockTimeout defines -lock-timeout in the CLI as how long to wait for another igo
+	// that is installing the same version or changing the workspace
+	kLockTimeout string = "lock-timeout"
+
+	// kFormat defines -format in the CLI as table, plain, json or yaml for -l, -e and -version
+	kFormat string = "format"
+
+	// kRC defines -rc in the CLI that makes -init add its managed block to the rc file
+	kRC string = "rc"
+
+	// kRCFile defines -rc-file in the CLI that overrides the rc file of -rc and -rc-remove
+	kRCFile string = "rc-file"
+
+	// kRCRemove defines -rc-remove in the CLI that makes -init remove its managed block
+	kRCRemove string = "rc-remove"
+
+	// kUnset defines -unset in the CLI that removes the .go_version of the current project
+	kUnset string = "unset"
+
+	// kToolchain defines -toolchain in the CLI that makes -pin and -unset update the
+	// toolchain directive of go.mod
+	kToolchain string = "toolchain"
+
+	// kHook defines -hook in the CLI that adds the directory change hook to -init
+	kHook string = "hook"
+
+	// kAutoInstall defines -auto-install in the CLI that lets the -init hook and -pin
+	// install the version of go that a directory asks for when it is missing
+	kAutoInstall string = "auto-install"
+
+	// kProfiles defines -profiles in the CLI that lets install edit the shell profiles
+	kProfiles string = "profiles"
+
+	// kDryRun defines -dry-run in the CLI that makes -prune only report what it would remove
+	kDryRun string = "dry-run"
+
+	// kKeepPatches defines -keep-patches in the CLI that makes -prune keep the newest N
+	// patch releases of each minor version
+	kKeepPatches string = "keep-patches"
+
+	// kUnusedDays defines -unused-days in the CLI that makes -prune remove versions that
+	// have not been used for N days
+	kUnusedDays string = "unused-days"
+
+	// kPruneDownloads defines -prune-downloads in the CLI that makes -prune delete the
+	// downloaded tarballs
+	kPruneDownloads string = "prune-downloads"
+
+	// kPruneCache defines -prune-cache in the CLI that makes -prune trim GOCACHE
+	kPruneCache string = "prune-cache"
+
+	// kInstallFile defines -install-file in the CLI as a file that lists the versions of
+	// go for -i to install, one selector per line
+	kInstallFile string = "install-file"
+
+	// kJobs defines -jobs in the CLI as how many versions of go -i installs at once
+	kJobs string = "jobs"
+
+	// kManifest defines -manifest in the CLI as the file that describes the toolchain
+	// that -sync makes the machine match
+	kManifest string = "manifest"
+
+	// kRemoveUnlisted defines -remove-unlisted in the CLI that lets -sync uninstall the
+	// versions that the manifest does not list
+	kRemoveUnlisted string = "remove-unlisted"
+
+	// kRepair defines -repair in the CLI that lets -doctor fix what it finds
+	kRepair string = "repair"
+
 	kDebug   string = "debug"
 	kVerbose string = "verbose"
 )
 
-// packages are installed after a new version of Go is installed
+// packages are the default -extra-packages installed after a new version of Go is
+// installed, each value is a module path with an optional @version pin
 var packages = map[string]string{
 	"genwordpass":          "github.com/ProjectApario/genwordpass",
 	"summarize":            "github.com/andreimerlescu/summarize",
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// severities of a finding, only severityError makes doctor fail
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+// finding is a broken invariant of the workspace that doctor reports and, when it
+// knows how, repairs
+type finding struct {
+	Check    string `json:"check" yaml:"check"`
+	Severity string `json:"severity" yaml:"severity"`
+	Path     string `json:"path" yaml:"path"`
+	Message  string `json:"message" yaml:"message"`
+	Repaired bool   `json:"repaired" yaml:"repaired"`
+	// repair fixes the finding, it is nil when doctor can only report it
+	repair func() error
+}
+
+// diagnose runs every check of doctor against the workspace and the shell profiles
+func (app *Application) diagnose() []finding {
+	workspace := app.Workspace()
+	var findings []finding
+	versions, _ := installedVersions(workspace)
+	active, activeErr := app.activatedVersion()
+	switch {
+	case activeErr != nil && len(versions) > 0:
+		findings = append(findings, finding{
+			Check:    "version",
+			Severity: severityWarning,
+			Path:     filepath.Join(workspace, "version"),
+			Message:  fmt.Sprintf("no version of go is activated, run: igo -%s <version>", cmdSwitch),
+		})
+	case activeErr == nil && !slices.Contains(versions, active):
+		findings = append(findings, finding{
+			Check:    "version",
+			Severity: severityError,
+			Path:     filepath.Join(workspace, "version"),
+			Message:  fmt.Sprintf("the activated go %s is not installed, run: igo -%s %s", active, cmdInstall, active),
+		})
+	case activeErr == nil:
+		findings = append(findings, diagnoseSymlinks(workspace, active)...)
+	}
+	findings = append(findings, diagnoseShims(app, workspace)...)
+	for _, version := range versions {
+		findings = append(findings, diagnoseBinaries(workspace, version)...)
+	}
+	findings = append(findings, diagnoseProfiles(app, workspace)...)
+	findings = append(findings, diagnoseLeftovers(workspace)...)
+	findings = append(findings, diagnoseLocks(workspace)...)
+	return findings
+}
+
+// diagnoseSymlinks checks that root, bin and path point into the activated version
+func diagnoseSymlinks(workspace, version string) []finding {
+	versionDir := filepath.Join(workspace, "versions", version)
+	expected := []struct{ name, target string }{
+		{"root", filepath.Join(versionDir, "go")},
+		{"bin", filepath.Join(versionDir, "go", "bin")},
+		{"path", versionDir},
+	}
+	var findings []finding
+	for _, link := range expected {
+		path := filepath.Join(workspace, link.name)
+		relink := func() error {
+			if err := internal.RemoveSymlinkOrBackupPath(path); err != nil {
+				return err
+			}
+			return os.Symlink(link.target, path)
+		}
+		f := finding{Check: "symlink", Severity: severityError, Path: path, repair: relink}
+		info, err := os.Lstat(path)
+		switch {
+		case os.IsNotExist(err):
+			f.Message = fmt.Sprintf("%s is missing, it should point to %s", link.name, link.target)
+		case err != nil:
+			f.Message, f.repair = err.Error(), nil
+		case info.Mode()&os.ModeSymlink == 0:
+			f.Message = fmt.Sprintf("%s is not a symlink, it should point to %s", link.name, link.target)
+		default:
+			target, err := internal.ReadSymlink(path)
+			if err != nil {
+				f.Message = err.Error()
+			} else if internal.VerifyLink(path, link.target) != "✅" {
+				f.Message = fmt.Sprintf("%s points to %s instead of the activated go %s at %s", link.name, target, version, link.target)
+			} else if _, err := os.Stat(target); err != nil {
+				f.Message = fmt.Sprintf("%s points to %s which does not exist", link.name, target)
+				f.repair = nil
+			} else {
+				continue
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// diagnoseShims checks that every shim is a symlink to the running igo binary
+func diagnoseShims(app *Application, workspace string) []finding {
+	self, err := os.Executable()
+	if err != nil {
+		return []finding{{Check: "shim", Severity: severityWarning, Message: err.Error()}}
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+	var findings []finding
+	for _, name := range shimNames {
+		path := filepath.Join(workspace, "shims", name)
+		f := finding{Check: "shim", Severity: severityError, Path: path, repair: app.CreateShims}
+		target, err := filepath.EvalSymlinks(path)
+		switch {
+		case os.IsNotExist(err):
+			if _, lstatErr := os.Lstat(path); lstatErr == nil {
+				f.Message = fmt.Sprintf("shim %s is a broken symlink", name)
+			} else {
+				f.Message = fmt.Sprintf("shim %s is missing", name)
+			}
+		case err != nil:
+			f.Message = err.Error()
+		case target != self:
+			f.Message = fmt.Sprintf("shim %s runs %s instead of %s", name, target, self)
+		default:
+			continue
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// diagnoseBinaries checks that install renamed go and gofmt to name.version and
+// finished the install of version
+func diagnoseBinaries(workspace, version string) []finding {
+	versionDir := filepath.Join(workspace, "versions", version)
+	binDir := filepath.Join(versionDir, "go", "bin")
+	var findings []finding
+	for _, name := range shimNames {
+		versioned := filepath.Join(binDir, name+"."+version)
+		if _, err := os.Stat(versioned); err == nil {
+			continue
+		}
+		plain := filepath.Join(binDir, name)
+		f := finding{Check: "binary", Severity: severityError, Path: versioned}
+		if _, err := os.Stat(plain); err == nil {
+			f.Message = fmt.Sprintf("%s of go %s was not renamed to %s", name, version, filepath.Base(versioned))
+			f.repair = func() error { return os.Rename(plain, versioned) }
+		} else {
+			f.Message = fmt.Sprintf("%s of go %s is missing, run: igo -%s %s", name, version, cmdUninstall, version)
+		}
+		findings = append(findings, f)
+	}
+	if _, err := os.Stat(filepath.Join(versionDir, "installer.lock")); os.IsNotExist(err) {
+		findings = append(findings, finding{
+			Check:    "binary",
+			Severity: severityWarning,
+			Path:     versionDir,
+			Message:  fmt.Sprintf("the install of go %s did not finish, reinstall it with igo -%s %s and igo -%s %s", version, cmdUninstall, version, cmdInstall, version),
+		})
+	}
+	return findings
+}
+
+// diagnoseProfiles checks the shell profiles for exports that are repeated and for
+// a PATH that does not put the shims before the bin directory
+func diagnoseProfiles(app *Application, workspace string) []finding {
+	shimDir := filepath.Join(workspace, "shims")
+	binDir := filepath.Join(workspace, "bin")
+	scriptsDir := filepath.Join(workspace, "scripts")
+	var findings []finding
+	foundPath := false
+	for _, profile := range app.shellConfigFiles() {
+		content, err := os.ReadFile(profile)
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(string(content), "\n")
+		for name, indexes := range profileExports(lines) {
+			if name == "PATH" || len(indexes) < 2 {
+				continue
+			}
+			findings = append(findings, finding{
+				Check:    "profile",
+				Severity: severityWarning,
+				Path:     profile,
+				Message:  fmt.Sprintf("%s is exported %d times", name, len(indexes)),
+				repair: func() error {
+					return editProfile(profile, func(lines []string) []string {
+						// the shell keeps the last export so the earlier ones are removed
+						indexes := profileExports(lines)[name]
+						for _, i := range slices.Backward(indexes[:len(indexes)-1]) {
+							lines = slices.Delete(lines, i, i+1)
+						}
+						return lines
+					})
+				},
+			})
+		}
+		for _, i := range profileExports(lines)["PATH"] {
+			parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(lines[i]), "export PATH="), ":")
+			shims, bin := slices.Index(parts, shimDir), slices.Index(parts, binDir)
+			if shims < 0 && bin < 0 {
+				continue
+			}
+			foundPath = true
+			if shims >= 0 && (bin < 0 || shims < bin) {
+				continue
+			}
+			findings = append(findings, finding{
+				Check:    "profile",
+				Severity: severityWarning,
+				Path:     profile,
+				Message:  fmt.Sprintf("PATH does not list %s before %s so the shims cannot pick the version of go", shimDir, binDir),
+				repair: func() error {
+					return editProfile(profile, func(lines []string) []string {
+						for _, i := range profileExports(lines)["PATH"] {
+							lines[i] = "export PATH=" + strings.Join(prependPaths(
+								strings.Split(strings.TrimPrefix(strings.TrimSpace(lines[i]), "export PATH="), ":"),
+								shimDir, binDir, scriptsDir), ":")
+						}
+						return lines
+					})
+				},
+			})
+		}
+	}
+	if !foundPath && !app.hasManagedBlock() {
+		envs := map[string]string{GOSHIMS: shimDir, GOBIN: binDir, GOSCRIPTS: scriptsDir}
+		findings = append(findings, finding{
+			Check:    "profile",
+			Severity: severityWarning,
+			Path:     app.UserHomeDir,
+			Message:  fmt.Sprintf("no shell profile adds %s to PATH", shimDir),
+			repair:   func() error { return app.patchShellConfigPath(envs) },
+		})
+	}
+	return findings
+}
+
+// profileExports returns the indexes of the lines that export each variable
+func profileExports(lines []string) map[string][]int {
+	exports := map[string][]int{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "export ") {
+			continue
+		}
+		name, _, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if ok {
+			exports[name] = append(exports[name], i)
+		}
+	}
+	return exports
+}
+
+// prependPaths moves first to the front of parts in order, dropping their duplicates
+func prependPaths(parts []string, first ...string) []string {
+	out := slices.Clone(first)
+	for _, part := range parts {
+		if !slices.Contains(first, part) {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// editProfile rewrites the lines of the shell profile at path with edit
+func editProfile(path string, edit func([]string) []string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return internal.ErrFile{Path: path, Err: err, How: "os.Stat"}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return internal.ErrFile{Path: path, Err: err, How: "os.ReadFile"}
+	}
+	lines := edit(strings.Split(string(content), "\n"))
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+}
+
+// diagnoseLeftovers finds the .bak directories of RemoveSymlinkOrBackupPath and the
+// staging and rollback directories of installs that were interrupted
+func diagnoseLeftovers(workspace string) []finding {
+	var paths []string
+	for _, name := range []string{"root", "bin", "path"} {
+		paths = append(paths, filepath.Join(workspace, name+".bak"))
+	}
+	for _, pattern := range []string{".*.staging-*", "*.rollback-*"} {
+		matches, _ := filepath.Glob(filepath.Join(workspace, "versions", pattern))
+		paths = append(paths, matches...)
+	}
+	var findings []finding
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		findings = append(findings, finding{
+			Check:    "leftover",
+			Severity: severityWarning,
+			Path:     path,
+			Message:  fmt.Sprintf("%s was left behind by an earlier igo", filepath.Base(path)),
+			repair: func() error {
+				if err := internal.MakeDirsWritable(path); err != nil {
+					return err
+				}
+				return os.RemoveAll(path)
+			},
+		})
+	}
+	return findings
+}
+
+// diagnoseLocks reports lock files that name an igo which exited without unlocking
+// and locks that are held while doctor runs
+func diagnoseLocks(workspace string) []finding {
+	paths, _ := filepath.Glob(filepath.Join(workspace, "locks", "*.lock"))
+	paths = append(paths, workspaceLockPath(workspace))
+	var findings []finding
+	for _, path := range paths {
+		pid, held, err := inspectLock(path)
+		switch {
+		case err != nil || pid == 0 || pid == os.Getpid():
+			continue
+		case held && processAlive(pid):
+			findings = append(findings, finding{
+				Check:    "lock",
+				Severity: severityInfo,
+				Path:     path,
+				Message:  fmt.Sprintf("igo pid %d is holding the lock", pid),
+			})
+		case held:
+			findings = append(findings, finding{
+				Check:    "lock",
+				Severity: severityError,
+				Path:     path,
+				Message:  fmt.Sprintf("the lock is held but igo pid %d is no longer running, the lock is stale and is released once the process holding it exits", pid),
+			})
+		default:
+			findings = append(findings, finding{
+				Check:    "lock",
+				Severity: severityWarning,
+				Path:     path,
+				Message:  fmt.Sprintf("igo pid %d exited without releasing the lock", pid),
+				repair:   func() error { return os.Truncate(path, 0) },
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenWorkspace creates a workspace and home directory with one problem for each
+// check of doctor
+func brokenWorkspace(t *testing.T) (workspace, home string) {
+	workspace, home = t.TempDir(), t.TempDir()
+	for _, version := range []string{"1.21.0", "1.22.1"} {
+		bin := filepath.Join(workspace, "versions", version, "go", "bin")
+		require.NoError(t, os.MkdirAll(bin, 0755))
+		for _, name := range shimNames {
+			require.NoError(t, os.WriteFile(filepath.Join(bin, name+"."+version), []byte("1234"), 0755))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, "versions", version, "installer.lock"), nil, 0644))
+	}
+	require.NoError(t, os.Rename(
+		filepath.Join(workspace, "versions", "1.21.0", "go", "bin", "go.1.21.0"),
+		filepath.Join(workspace, "versions", "1.21.0", "go", "bin", "go")))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "version"), []byte("1.22.1"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(workspace, "versions", "1.22.1", "go"), filepath.Join(workspace, "root")))
+	require.NoError(t, os.Symlink(filepath.Join(workspace, "versions", "1.21.0", "go", "bin"), filepath.Join(workspace, "bin")))
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "path.bak"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "shims"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "locks"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "locks", "1.21.0.lock"), []byte("999999999"), 0644))
+	profile := strings.Join([]string{
+		"export GOBIN=/old/bin",
+		fmt.Sprintf("export GOBIN=%s", filepath.Join(workspace, "bin")),
+		fmt.Sprintf("export PATH=%s:%s:/usr/bin", filepath.Join(workspace, "bin"), filepath.Join(workspace, "shims")),
+		"",
+	}, "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".profile"), []byte(profile), 0644))
+	return workspace, home
+}
+
+func TestDoctor(t *testing.T) {
+	workspace, home := brokenWorkspace(t)
+	homeDir := UserHomeDir
+	UserHomeDir = func() (string, error) { return home, nil }
+	defer func() { UserHomeDir = homeDir }()
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-doctor"}
+	findings := NewApp().diagnose()
+	paths := map[string]string{}
+	for _, f := range findings {
+		paths[f.Path] = f.Severity
+	}
+	assert.Equal(t, severityError, paths[filepath.Join(workspace, "bin")])
+	assert.Equal(t, severityError, paths[filepath.Join(workspace, "path")])
+	assert.Equal(t, severityError, paths[filepath.Join(workspace, "shims", "go")])
+	assert.Equal(t, severityError, paths[filepath.Join(workspace, "versions", "1.21.0", "go", "bin", "go.1.21.0")])
+	assert.Equal(t, severityWarning, paths[filepath.Join(workspace, "path.bak")])
+	assert.Equal(t, severityWarning, paths[filepath.Join(workspace, "locks", "1.21.0.lock")])
+	assert.Equal(t, severityWarning, paths[filepath.Join(home, ".profile")])
+	assert.NotContains(t, paths, filepath.Join(workspace, "root"))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-doctor", "-format", "json"}
+	app := NewApp()
+	out, err := captureOutput(t, func() error { return doctor(app) })
+	var unhealthy internal.ErrUnhealthy
+	require.ErrorAs(t, err, &unhealthy)
+	assert.Equal(t, 5, unhealthy.Problems)
+	assert.Equal(t, exitUnhealthy, exitCode(err))
+	var records []finding
+	require.NoError(t, json.Unmarshal([]byte(out), &records))
+	assert.Len(t, records, len(findings))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-doctor", "-repair", "-format", "plain"}
+	app = NewApp()
+	out, err = captureOutput(t, func() error { return doctor(app) })
+	require.NoError(t, err)
+	assert.Contains(t, out, "warning\tleftover\t"+filepath.Join(workspace, "path.bak"))
+
+	remaining := NewApp().diagnose()
+	assert.Empty(t, remaining)
+	assert.FileExists(t, filepath.Join(workspace, "versions", "1.21.0", "go", "bin", "go.1.21.0"))
+	assert.NoDirExists(t, filepath.Join(workspace, "path.bak"))
+	target, err := os.Readlink(filepath.Join(workspace, "bin"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(workspace, "versions", "1.22.1", "go", "bin"), target)
+	profile, err := os.ReadFile(filepath.Join(home, ".profile"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(profile), "export GOBIN="))
+	assert.NotContains(t, string(profile), "/old/bin")
+	assert.Contains(t, string(profile), fmt.Sprintf("export PATH=%s:%s:", filepath.Join(workspace, "shims"), filepath.Join(workspace, "bin")))
+}
+
+func TestFix(t *testing.T) {
+	workspace := fakeWorkspace(t)
+	os.Args = []string{os.Args[0], "-godir", workspace, "-f", "1.22.1"}
+	require.NoError(t, fix(NewApp(), "1.22.1"))
+	// path points at the GOPATH of the version like install and use make it
+	target, err := os.Readlink(filepath.Join(workspace, "path"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(workspace, "versions", "1.22.1"), target)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// exit codes returned by igo so that scripts can tell failures apart
+const (
+	exitOK              = 0
+	exitFailure         = 1
+	exitUsage           = 2
+	exitBadVersion      = 3
+	exitNotInstalled    = 4
+	exitDownload        = 5
+	exitChecksum        = 6
+	exitLocked          = 7
+	exitFilesystem      = 8
+	exitVersionMismatch = 9
+	exitUnhealthy       = 10
+	exitDrift           = 11
+	exitCommandNotFound = 127
+)
+
+// exitCode maps the typed errors of internal/errors.go to the exit code of igo
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var (
+		usage        internal.ErrUsage
+		badVersion   internal.ErrBadVersion
+		notInstalled internal.ErrNotInstalled
+		noGo         internal.ErrNoGoInstalled
+		checksum     internal.ErrChecksumMismatch
+		download     internal.ErrDownload
+		locked       internal.ErrLocked
+		mismatch     internal.ErrVersionMismatch
+		unhealthy    internal.ErrUnhealthy
+		drift        internal.ErrDrift
+		notFound     internal.ErrCommandNotFound
+		file         internal.ErrFile
+		dirEntries   internal.ErrDirEntries
+		pathFailed   internal.ErrPathFailed
+		pathErr      *fs.PathError
+		linkErr      *os.LinkError
+		exitErr      *exec.ExitError
+	)
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() > 0:
+		// igo -i run by a shim already printed its error and chose its exit code
+		return exitErr.ExitCode()
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &badVersion):
+		return exitBadVersion
+	case errors.As(err, &notInstalled), errors.As(err, &noGo):
+		return exitNotInstalled
+	case errors.As(err, &checksum):
+		return exitChecksum
+	case errors.As(err, &download):
+		return exitDownload
+	case errors.As(err, &locked):
+		return exitLocked
+	case errors.As(err, &mismatch):
+		return exitVersionMismatch
+	case errors.As(err, &unhealthy):
+		return exitUnhealthy
+	case errors.As(err, &drift):
+		return exitDrift
+	case errors.As(err, &notFound):
+		return exitCommandNotFound
+	case errors.As(err, &file), errors.As(err, &dirEntries), errors.As(err, &pathFailed),
+		errors.As(err, &pathErr), errors.As(err, &linkErr):
+		return exitFilesystem
+	}
+	return exitFailure
+}
+
+// exit prints err on stderr and exits igo with its exitCode
+func exit(err error) {
+	if err != nil {
+		message := fmt.Sprintf("ERROR: %s", err)
+		if colorSupported(os.Stderr) {
+			red := color.New(color.FgRed)
+			red.EnableColor()
+			message = red.Sprint(message)
+		}
+		_, _ = fmt.Fprintln(os.Stderr, message)
+	}
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 7").Run()
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, exitOK},
+		{"generic", errors.New("boom"), exitFailure},
+		{"usage", internal.ErrUsage{Usage: "igo -exec"}, exitUsage},
+		{"bad version", internal.ErrBadVersion{Version: "x", Err: errors.New("invalid")}, exitBadVersion},
+		{"not installed", internal.ErrNotInstalled{Version: "1.20.0"}, exitNotInstalled},
+		{"no go", internal.ErrNoGoInstalled{Workspace: "/tmp/go"}, exitNotInstalled},
+		{"download", internal.ErrDownload{Name: "go.tar.gz", Err: errors.New("404")}, exitDownload},
+		{"checksum", internal.ErrChecksumMismatch{Path: "go.tar.gz"}, exitChecksum},
+		{"locked", internal.ErrLocked{Path: "installer.lock", PID: 1}, exitLocked},
+		{"mismatch", internal.ErrVersionMismatch{Want: "1.20.0", Got: "go1.19"}, exitVersionMismatch},
+		{"drift", internal.ErrDrift{Manifest: "igo.yml", Drift: 2}, exitDrift},
+		{"command not found", internal.ErrCommandNotFound{Name: "nope", Err: exec.ErrNotFound}, exitCommandNotFound},
+		{"filesystem", &os.PathError{Op: "open", Path: "/nope", Err: os.ErrNotExist}, exitFilesystem},
+		{"wrapped", fmt.Errorf("install: %w", internal.ErrFile{Path: "x", Err: os.ErrPermission, How: "open"}), exitFilesystem},
+		{"bad version wraps download", internal.ErrBadVersion{Version: "x", Err: internal.ErrDownload{Name: "x", Err: errors.New("offline")}}, exitBadVersion},
+		{"child exit code", exitErr, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	workspace := t.TempDir()
+
+	os.Args = []string{os.Args[0], "-godir", filepath.Join(workspace, "missing"), "-l"}
+	assert.ErrorAs(t, run(NewApp()), &internal.ErrNoGoInstalled{})
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-s", "not-a-version"}
+	err := run(NewApp())
+	assert.ErrorAs(t, err, &internal.ErrBadVersion{})
+	assert.Equal(t, exitBadVersion, exitCode(err))
+
+	os.Args = []string{os.Args[0], "-godir", workspace}
+	app := NewApp()
+	err = use(app, "1.20.0")
+	assert.ErrorAs(t, err, &internal.ErrNotInstalled{})
+	assert.Equal(t, exitNotInstalled, exitCode(err))
+	require.ErrorAs(t, uninstall(app, "1.20.0"), &internal.ErrNotInstalled{})
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v3"
+)
+
+// formats accepted by -format, formatTable is the decorated output for humans
+const (
+	formatTable = "table"
+	formatPlain = "plain"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// versionRecord describes an installed version of go for -format
+type versionRecord struct {
+	Version    string    `json:"version" yaml:"version"`
+	Path       string    `json:"path" yaml:"path"`
+	Size       int64     `json:"size" yaml:"size"`
+	Created    time.Time `json:"created" yaml:"created"`
+	Active     bool      `json:"active" yaml:"active"`
+	PreRelease bool      `json:"pre_release" yaml:"pre_release"`
+	// LastUsed is when a shim or -exec last ran the version, nil when it never ran
+	LastUsed *time.Time `json:"last_used" yaml:"last_used"`
+	// LastUsedDir is the directory that the version last ran in
+	LastUsedDir string `json:"last_used_dir,omitempty" yaml:"last_used_dir,omitempty"`
+	Uses        int64  `json:"uses" yaml:"uses"`
+}
+
+// linkRecord describes a symlink in the workspace for -format
+type linkRecord struct {
+	Link     string `json:"link" yaml:"link"`
+	Target   string `json:"target" yaml:"target"`
+	Verified bool   `json:"verified" yaml:"verified"`
+	Exists   bool   `json:"exists" yaml:"exists"`
+}
+
+// envRecord describes the environment of the activated version of go for -format
+type envRecord struct {
+	Version   string            `json:"version" yaml:"version"`
+	Workspace string            `json:"workspace" yaml:"workspace"`
+	Env       map[string]string `json:"env" yaml:"env"`
+	Path      []string          `json:"path" yaml:"path"`
+	Links     []linkRecord      `json:"links" yaml:"links"`
+}
+
+// whichRecord describes the version of go that runs in a directory for -format
+type whichRecord struct {
+	Directory string `json:"directory" yaml:"directory"`
+	Version   string `json:"version" yaml:"version"`
+	Reason    string `json:"reason" yaml:"reason"`
+	// Source is the file that decided the Version, empty when it came from the environment
+	Source    string `json:"source,omitempty" yaml:"source,omitempty"`
+	Line      int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Binary    string `json:"binary" yaml:"binary"`
+	Installed bool   `json:"installed" yaml:"installed"`
+	// NoInstall is true when GOTOOLCHAIN forbids the shims to install a missing Version
+	NoInstall bool `json:"no_install" yaml:"no_install"`
+	// Global is the activated version that runs outside of projects
+	Global string `json:"global,omitempty" yaml:"global,omitempty"`
+}
+
+// binaryRecord describes the igo binary for -format
+type binaryRecord struct {
+	Version string `json:"version" yaml:"version"`
+	About   string `json:"about" yaml:"about"`
+}
+
+// outputFormat returns the validated -format
+func (app *Application) outputFormat() (string, error) {
+	format := strings.ToLower(strings.TrimSpace(*app.Figs.String(kFormat)))
+	switch format {
+	case "":
+		return formatTable, nil
+	case formatTable, formatPlain, formatJSON, formatYAML:
+		return format, nil
+	}
+	return "", internal.ErrUsage{Usage: fmt.Sprintf("-%s %s|%s|%s|%s", kFormat, formatTable, formatPlain, formatJSON, formatYAML)}
+}
+
+// configureColor disables colors when NO_COLOR is set, TERM is dumb, stdout is not
+// a terminal or the output is meant to be parsed
+func configureColor(format string) {
+	color.NoColor = !colorSupported(os.Stdout) || format != formatTable
+}
+
+// colorSupported reports whether f is a terminal that accepts colors
+func colorSupported(f *os.File) bool {
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// writeRecords encodes v as json or yaml on w
+func writeRecords(w io.Writer, format string, v any) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case formatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("format %s does not encode records", format)
+}
+
+// dirSize returns the total size of the regular files under path
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// versionRecords returns the installed versions of go from newest to oldest, sizes
+// are only measured when withSize is true since it walks every file
+func (app *Application) versionRecords(withSize bool) ([]versionRecord, error) {
+	workspace := app.Workspace()
+	versions, err := app.findGoVersions()
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(versions)
+	currentVersion, _ := app.activatedVersion()
+	usage := usageOrEmpty(workspace)
+	records := make([]versionRecord, 0, len(versions))
+	for _, version := range versions {
+		path := filepath.Join(workspace, "versions", version)
+		created, err := versionInstalledAt(workspace, version)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, internal.ErrFile{Path: path, Err: err, How: "os.Stat"}
+		}
+		record := versionRecord{
+			Version: version,
+			Path:    path,
+			Created: created,
+			Active:  strings.EqualFold(version, currentVersion),
+		}
+		if used, ok := usage[version]; ok {
+			record.LastUsed, record.LastUsedDir, record.Uses = &used.LastUsed, used.Directory, used.Count
+		}
+		if v, ok := parseGoVersion(version); ok {
+			record.PreRelease = v.IsPreRelease()
+		}
+		if withSize {
+			if record.Size, err = dirSize(path); err != nil {
+				return nil, internal.ErrFile{Path: path, Err: err, How: "size"}
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// versionInstalledAt returns when the install of version finished, which install
+// records on versions/<version>/installer.lock, or the modification time of the
+// version directory when it has no marker
+func versionInstalledAt(workspace, version string) (time.Time, error) {
+	versionDir := filepath.Join(workspace, "versions", version)
+	if info, err := os.Stat(filepath.Join(versionDir, "installer.lock")); err == nil {
+		return info.ModTime(), nil
+	}
+	info, err := os.Stat(versionDir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// envRecord collects the GO environment, the PATH entries igo requires and the
+// symlinks of the workspace, filling in the igo defaults of unset variables
+func (app *Application) envRecord() (envRecord, error) {
+	workspace := app.Workspace()
+	currentVersion, err := app.activatedVersion()
+	if err != nil && !os.IsNotExist(err) {
+		return envRecord{}, err
+	}
+	record := envRecord{
+		Version:   currentVersion,
+		Workspace: workspace,
+		Env:       map[string]string{},
+		Path:      []string{filepath.Join(workspace, "bin"), filepath.Join(workspace, "shims")},
+	}
+	for _, val := range os.Environ() {
+		parts := strings.SplitN(val, "=", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[0], "GO") {
+			record.Env[parts[0]] = parts[1]
+		}
+	}
+	defaults := map[string]string{
+		GOBIN:      filepath.Join(workspace, "bin"),
+		GOPATH:     filepath.Join(workspace, "path"),
+		GOROOT:     filepath.Join(workspace, "root"),
+		GOMODCACHE: filepath.Join(workspace, "versions", currentVersion, "go", "pkg", "mod"),
+	}
+	for name, value := range defaults {
+		if _, ok := record.Env[name]; !ok {
+			record.Env[name] = value
+		}
+	}
+	slices.Sort(record.Path)
+	links, err := internal.FindSymlinks(workspace)
+	if err != nil {
+		return record, err
+	}
+	slices.Sort(links)
+	for _, link := range links {
+		target, err := internal.ReadSymlink(link)
+		if err != nil {
+			continue
+		}
+		_, statErr := os.Stat(target)
+		record.Links = append(record.Links, linkRecord{
+			Link:     link,
+			Target:   target,
+			Verified: internal.VerifyLink(link, target) == "✅",
+			Exists:   statErr == nil,
+		})
+	}
+	return record, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// captureStdout returns what fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	out, err := captureOutput(t, fn)
+	require.NoError(t, err)
+	return out
+}
+
+// captureOutput returns what fn writes to os.Stdout and color.Output along with the
+// error of fn
+func captureOutput(t *testing.T, fn func() error) (string, error) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout, output := os.Stdout, color.Output
+	os.Stdout, color.Output = w, w
+	defer func() { os.Stdout, color.Output = stdout, output }()
+	// read while fn runs so that output beyond the pipe buffer does not block it
+	out := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- b
+	}()
+	fnErr := fn()
+	require.NoError(t, w.Close())
+	return string(<-out), fnErr
+}
+
+// fakeWorkspace creates versions 1.21.0 and 1.22.1 with 1.22.1 activated
+func fakeWorkspace(t *testing.T) string {
+	workspace := t.TempDir()
+	for _, version := range []string{"1.21.0", "1.22.1"} {
+		bin := filepath.Join(workspace, "versions", version, "go", "bin")
+		require.NoError(t, os.MkdirAll(bin, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "go."+version), []byte("1234"), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "version"), []byte("1.22.1"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(workspace, "versions", "1.22.1", "go"), filepath.Join(workspace, "root")))
+	require.NoError(t, os.Symlink(filepath.Join(workspace, "versions", "1.20.0", "go", "bin"), filepath.Join(workspace, "bin")))
+	return workspace
+}
+
+func TestApplication_outputFormat(t *testing.T) {
+	for _, format := range []string{"table", "plain", "json", "YAML"} {
+		os.Args = []string{os.Args[0], "-format", format}
+		got, err := NewApp().outputFormat()
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToLower(format), got)
+	}
+	os.Args = []string{os.Args[0], "-format", "xml"}
+	_, err := NewApp().outputFormat()
+	assert.ErrorAs(t, err, &internal.ErrUsage{})
+}
+
+func TestList_formats(t *testing.T) {
+	workspace := fakeWorkspace(t)
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-format", "json"}
+	app := NewApp()
+	var records []versionRecord
+	require.NoError(t, json.Unmarshal([]byte(captureStdout(t, func() error { return list(app) })), &records))
+	require.Len(t, records, 2)
+	assert.Equal(t, "1.22.1", records[0].Version)
+	assert.True(t, records[0].Active)
+	assert.Equal(t, int64(4), records[0].Size)
+	assert.Equal(t, filepath.Join(workspace, "versions", "1.21.0"), records[1].Path)
+	assert.False(t, records[1].Active)
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-format", "yaml"}
+	app = NewApp()
+	records = nil
+	require.NoError(t, yaml.Unmarshal([]byte(captureStdout(t, func() error { return list(app) })), &records))
+	require.Len(t, records, 2)
+	assert.Equal(t, "1.21.0", records[1].Version)
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-format", "plain"}
+	app = NewApp()
+	lines := strings.Split(strings.TrimSpace(captureStdout(t, func() error { return list(app) })), "\n")
+	require.Len(t, lines, 2)
+	fields := strings.Split(lines[0], "\t")
+	require.Len(t, fields, 7)
+	assert.Equal(t, []string{"1.22.1", filepath.Join(workspace, "versions", "1.22.1"), "4"}, fields[:3])
+	assert.Equal(t, []string{"true", "-", ""}, fields[4:])
+	assert.NotContains(t, lines[0], "\x1b[")
+}
+
+func TestEnv_json(t *testing.T) {
+	workspace := fakeWorkspace(t)
+	t.Setenv("GOROOT", "/opt/custom/go")
+	t.Setenv("GOMODCACHE", "")
+	require.NoError(t, os.Unsetenv("GOMODCACHE"))
+	os.Args = []string{os.Args[0], "-godir", workspace, "-format", "json"}
+	app := NewApp()
+	var record envRecord
+	require.NoError(t, json.Unmarshal([]byte(captureStdout(t, func() error { return env(app) })), &record))
+	assert.Equal(t, "1.22.1", record.Version)
+	assert.Equal(t, "/opt/custom/go", record.Env[GOROOT])
+	// install puts the module cache of a version under its go directory
+	assert.Equal(t, filepath.Join(workspace, "versions", "1.22.1", "go", "pkg", "mod"), record.Env[GOMODCACHE])
+	assert.Equal(t, []string{filepath.Join(workspace, "bin"), filepath.Join(workspace, "shims")}, record.Path)
+	require.Len(t, record.Links, 2)
+	assert.Equal(t, filepath.Join(workspace, "bin"), record.Links[0].Link)
+	assert.True(t, record.Links[0].Verified)
+	assert.False(t, record.Links[0].Exists)
+	assert.Equal(t, filepath.Join(workspace, "root"), record.Links[1].Link)
+	assert.True(t, record.Links[1].Exists)
+}
+
+func TestColorSupported(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, colorSupported(f))
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, colorSupported(os.Stdout))
+}
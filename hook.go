@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// hookScript returns the code that runs -hook-env whenever the interactive shell
+// changes directory, sh has no such event so it gets none
+func (app *Application) hookScript(shell string) (string, error) {
+	self, err := igoExecutable()
+	if err != nil {
+		return "", err
+	}
+	workspace := app.Workspace()
+	// -hook-env only installs a missing version when the hook asks it to
+	autoInstall := ""
+	if *app.Figs.Bool(kAutoInstall) {
+		autoInstall = fmt.Sprintf(" -%s=true", kAutoInstall)
+	}
+	switch shell {
+	case shellBash:
+		return fmt.Sprintf(`_igo_hook() {
+  if [ "${_IGO_HOOK_PWD-}" != "$PWD" ]; then
+    _IGO_HOOK_PWD="$PWD"
+    eval "$(%s -%s bash -%s %s%s)"
+  fi
+}
+case ";${PROMPT_COMMAND-};" in *";_igo_hook;"*) ;; *) PROMPT_COMMAND="_igo_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;; esac
+`, shellQuote(self), cmdHookEnv, kGoDir, shellQuote(workspace), autoInstall), nil
+	case shellZsh:
+		return fmt.Sprintf(`_igo_hook() {
+  eval "$(%s -%s zsh -%s %s%s)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _igo_hook
+_igo_hook
+`, shellQuote(self), cmdHookEnv, kGoDir, shellQuote(workspace), autoInstall), nil
+	case shellFish:
+		return fmt.Sprintf(`function _igo_hook --on-variable PWD
+    %s -%s fish -%s %s%s | source
+end
+_igo_hook
+`, fishQuote(self), cmdHookEnv, kGoDir, fishQuote(workspace), autoInstall), nil
+	}
+	return "", nil
+}
+
+// hookPath puts bin right after the shims in path and drops the bin directories of
+// the versions that earlier directories exported
+func hookPath(path, workspace, bin string) []string {
+	versionsDir := filepath.Join(workspace, "versions") + string(filepath.Separator)
+	shims := filepath.Join(workspace, "shims")
+	var parts []string
+	for _, part := range filepath.SplitList(path) {
+		if strings.HasPrefix(part, versionsDir) && strings.HasSuffix(part, filepath.Join("go", "bin")) {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return slices.Insert(parts, slices.Index(parts, shims)+1, bin)
+}
+
+// hookEnv prints the exports of the version of go that applies to the current
+// directory for the -init hook to evaluate, installing it first with -auto-install
+func hookEnv(app *Application) error {
+	shell := strings.ToLower(strings.TrimSpace(*app.Figs.String(cmdHookEnv)))
+	if !slices.Contains([]string{shellSh, shellBash, shellZsh, shellFish}, shell) {
+		return internal.ErrUsage{Usage: fmt.Sprintf("-%s %s|%s|%s|%s", cmdHookEnv, shellSh, shellBash, shellZsh, shellFish)}
+	}
+	workspace := app.Workspace()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	source, err := resolveDirVersion(workspace, cwd)
+	if errors.Is(err, os.ErrNotExist) {
+		// nothing is activated and the directory asks for nothing, keep the environment
+		return nil
+	} else if err != nil {
+		return err
+	}
+	version := source.Version
+	if _, err := os.Stat(filepath.Join(workspace, "versions", version)); os.IsNotExist(err) {
+		if !*app.Figs.Bool(kAutoInstall) || source.NoInstall {
+			fmt.Fprintf(os.Stderr, "igo: go %s is not installed for %s, run: igo -%s %s\n", version, source, cmdInstall, version)
+			return nil
+		}
+		if err := installMissing(workspace, version); err != nil {
+			return err
+		}
+	}
+	envs := versionPaths(workspace, version)
+	envs[envHookVersion] = version
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	path := hookPath(os.Getenv("PATH"), workspace, envs[GOBIN])
+	if shell == shellFish {
+		for _, name := range names {
+			fmt.Printf("set -gx %s %s\n", name, fishQuote(envs[name]))
+		}
+		quoted := make([]string, len(path))
+		for i, part := range path {
+			quoted[i] = fishQuote(part)
+		}
+		fmt.Printf("set -gx PATH %s\n", strings.Join(quoted, " "))
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("export %s=%s\n", name, shellQuote(envs[name]))
+	}
+	fmt.Printf("export PATH=%s\n", shellQuote(strings.Join(path, string(filepath.ListSeparator))))
+	return nil
+}
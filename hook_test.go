@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookPath(t *testing.T) {
+	workspace := "/ws"
+	path := strings.Join([]string{"/ws/shims", "/ws/versions/1.21.0/go/bin", "/ws/bin", "/usr/bin"}, string(filepath.ListSeparator))
+	assert.Equal(t, []string{"/ws/shims", "/ws/versions/1.22.1/go/bin", "/ws/bin", "/usr/bin"},
+		hookPath(path, workspace, "/ws/versions/1.22.1/go/bin"))
+	assert.Equal(t, []string{"/ws/versions/1.22.1/go/bin", "/usr/bin"},
+		hookPath("/usr/bin", workspace, "/ws/versions/1.22.1/go/bin"), "without the shims bin goes first")
+}
+
+func TestHookEnv(t *testing.T) {
+	t.Setenv(envVersion, "")
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Setenv("GOWORK", "")
+	workspace := fakeWorkspace(t)
+	t.Setenv("PATH", strings.Join([]string{filepath.Join(workspace, "shims"), "/usr/bin"}, string(filepath.ListSeparator)))
+	project := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".go_version"), []byte("1.21.0\n"), 0644))
+	t.Chdir(project)
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-hook-env", "bash"}
+	app := NewApp()
+	out := captureStdout(t, func() error { return hookEnv(app) })
+	versionDir := filepath.Join(workspace, "versions", "1.21.0")
+	assert.Contains(t, out, "export GOROOT='"+filepath.Join(versionDir, "go")+"'\n")
+	assert.Contains(t, out, "export GOPATH='"+versionDir+"'\n")
+	assert.Contains(t, out, "export GOMODCACHE='"+filepath.Join(versionDir, "go", "pkg", "mod")+"'\n")
+	assert.Contains(t, out, "export IGO_HOOK_VERSION='1.21.0'\n")
+	assert.Contains(t, out, "export PATH='"+strings.Join([]string{filepath.Join(workspace, "shims"), filepath.Join(versionDir, "go", "bin"), "/usr/bin"}, string(filepath.ListSeparator))+"'\n")
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-hook-env", "fish"}
+	app = NewApp()
+	out = captureStdout(t, func() error { return hookEnv(app) })
+	assert.Contains(t, out, "set -gx PATH '"+filepath.Join(workspace, "shims")+"' '"+filepath.Join(versionDir, "go", "bin")+"' '/usr/bin'\n")
+
+	// a missing version is reported on stderr and leaves the environment alone
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".go_version"), []byte("1.19.0\n"), 0644))
+	os.Args = []string{os.Args[0], "-godir", workspace, "-hook-env", "zsh"}
+	app = NewApp()
+	assert.Empty(t, captureStdout(t, func() error { return hookEnv(app) }))
+
+	// without a global version or a project there is nothing to change
+	require.NoError(t, os.Remove(filepath.Join(workspace, "version")))
+	t.Chdir(t.TempDir())
+	os.Args = []string{os.Args[0], "-godir", workspace, "-hook-env", "bash"}
+	app = NewApp()
+	out, err := captureOutput(t, func() error { return hookEnv(app) })
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestHookScript(t *testing.T) {
+	workspace := t.TempDir()
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "bash"}
+	script, err := NewApp().hookScript(shellBash)
+	require.NoError(t, err)
+	assert.NotContains(t, script, "-auto-install")
+
+	// -auto-install reaches the -hook-env that the hook runs
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "bash", "-auto-install"}
+	app := NewApp()
+	for _, shell := range []string{shellBash, shellZsh, shellFish} {
+		script, err := app.hookScript(shell)
+		require.NoError(t, err)
+		assert.Contains(t, script, " -hook-env "+shell+" ")
+		assert.Contains(t, script, " -auto-install=true", shell)
+	}
+}
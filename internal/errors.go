@@ -1,5 +1,7 @@
 package internal
 
+import "strconv"
+
 type ErrOSNotSupported struct {
 	OS string
 }
@@ -16,6 +18,10 @@ func (e ErrBitNotSet) Error() string {
 	return "failed to set setuid/setgid bits: " + e.Err.Error()
 }
 
+func (e ErrBitNotSet) Unwrap() error {
+	return e.Err
+}
+
 type ErrPathFailed struct {
 	Path string
 	Err  error
@@ -26,6 +32,10 @@ func (e ErrPathFailed) Error() string {
 	return "error doing " + e.Neg + " symlink " + e.Path + ": " + e.Err.Error()
 }
 
+func (e ErrPathFailed) Unwrap() error {
+	return e.Err
+}
+
 type ErrChmodFailed struct {
 	Path string
 	Err  error
@@ -35,6 +45,10 @@ func (e ErrChmodFailed) Error() string {
 	return "failed to chmod u+w on " + e.Path + ": " + e.Err.Error()
 }
 
+func (e ErrChmodFailed) Unwrap() error {
+	return e.Err
+}
+
 type ErrStickyBitsOnFile struct {
 	Path string
 }
@@ -53,6 +67,10 @@ func (e ErrStickyBitFailed) Error() string {
 	return "failed to " + e.How + " sticky bit: " + e.Err.Error()
 }
 
+func (e ErrStickyBitFailed) Unwrap() error {
+	return e.Err
+}
+
 type ErrSetUIDGIDBit struct {
 	Path string
 	Err  error
@@ -63,6 +81,10 @@ func (e ErrSetUIDGIDBit) Error() string {
 	return "tried to " + e.How + " setuid/setgid bits: " + e.Path
 }
 
+func (e ErrSetUIDGIDBit) Unwrap() error {
+	return e.Err
+}
+
 type ErrFile struct {
 	Path string
 	Err  error
@@ -73,6 +95,10 @@ func (e ErrFile) Error() string {
 	return e.How + " " + e.Path + " yields: " + e.Err.Error()
 }
 
+func (e ErrFile) Unwrap() error {
+	return e.Err
+}
+
 type ErrDirEntries struct {
 	Path string
 	Err  error
@@ -81,3 +107,134 @@ type ErrDirEntries struct {
 func (e ErrDirEntries) Error() string {
 	return "failed to read directory entries: " + e.Err.Error()
 }
+
+func (e ErrDirEntries) Unwrap() error {
+	return e.Err
+}
+
+type ErrChecksumMismatch struct {
+	Path   string
+	Want   string
+	Got    string
+	Pinned bool
+}
+
+func (e ErrChecksumMismatch) Error() string {
+	if e.Pinned {
+		return "pinned checksum for " + e.Path + " does not match the published one: expected " + e.Want + " got " + e.Got
+	}
+	return "checksum mismatch for " + e.Path + ": expected " + e.Want + " got " + e.Got
+}
+
+type ErrLocked struct {
+	Path  string
+	PID   int
+	Stale bool
+}
+
+func (e ErrLocked) Error() string {
+	owner := "another igo process"
+	if e.PID > 0 {
+		owner = "igo pid " + strconv.Itoa(e.PID)
+	}
+	if e.Stale {
+		return "timed out waiting for " + e.Path + " held by " + owner + " which is no longer running, the lock is stale and is released once the process holding it exits"
+	}
+	return "timed out waiting for " + e.Path + " held by " + owner
+}
+
+type ErrNoGoInstalled struct {
+	Workspace string
+}
+
+func (e ErrNoGoInstalled) Error() string {
+	return "no installed version of Go found in " + e.Workspace
+}
+
+type ErrNotInstalled struct {
+	Version string
+}
+
+func (e ErrNotInstalled) Error() string {
+	return "go " + e.Version + " is not installed, run: igo -i " + e.Version
+}
+
+type ErrBadVersion struct {
+	Version string
+	Err     error
+}
+
+func (e ErrBadVersion) Error() string {
+	return "bad version " + e.Version + ": " + e.Err.Error()
+}
+
+func (e ErrBadVersion) Unwrap() error {
+	return e.Err
+}
+
+type ErrVersionMismatch struct {
+	Want string
+	Got  string
+}
+
+func (e ErrVersionMismatch) Error() string {
+	return "mismatched versions got = " + e.Got + " ; wanted = " + e.Want
+}
+
+type ErrDownload struct {
+	Name string
+	Err  error
+}
+
+func (e ErrDownload) Error() string {
+	return "failed to download " + e.Name + ": " + e.Err.Error()
+}
+
+func (e ErrDownload) Unwrap() error {
+	return e.Err
+}
+
+type ErrDrift struct {
+	Manifest string
+	Drift    int
+}
+
+func (e ErrDrift) Error() string {
+	if e.Drift == 1 {
+		return "the workspace differs from " + e.Manifest + " in 1 place"
+	}
+	return "the workspace differs from " + e.Manifest + " in " + strconv.Itoa(e.Drift) + " places"
+}
+
+type ErrUsage struct {
+	Usage string
+}
+
+func (e ErrUsage) Error() string {
+	return "usage: " + e.Usage
+}
+
+type ErrCommandNotFound struct {
+	Name string
+	Err  error
+}
+
+func (e ErrCommandNotFound) Error() string {
+	return "command " + e.Name + " not found: " + e.Err.Error()
+}
+
+func (e ErrCommandNotFound) Unwrap() error {
+	return e.Err
+}
+
+type ErrUnhealthy struct {
+	Workspace string
+	Problems  int
+}
+
+func (e ErrUnhealthy) Error() string {
+	if e.Problems == 1 {
+		return "1 problem found in " + e.Workspace
+	}
+	return strconv.Itoa(e.Problems) + " problems found in " + e.Workspace
+}
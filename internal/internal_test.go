@@ -429,3 +429,28 @@ func TestUser(t *testing.T) {
 		t.Errorf("User() should return fallback user with Uid=-1, Username=nobody when user.Current fails")
 	}
 }
+
+func TestErrDrift(t *testing.T) {
+	if got := (ErrDrift{Manifest: "igo.yml", Drift: 1}).Error(); got != "the workspace differs from igo.yml in 1 place" {
+		t.Errorf("ErrDrift with 1 place, got: %s", got)
+	}
+	if got := (ErrDrift{Manifest: "igo.yml", Drift: 2}).Error(); got != "the workspace differs from igo.yml in 2 places" {
+		t.Errorf("ErrDrift with 2 places, got: %s", got)
+	}
+}
+
+func TestErrUnhealthy(t *testing.T) {
+	if got := (ErrUnhealthy{Workspace: "/go", Problems: 1}).Error(); got != "1 problem found in /go" {
+		t.Errorf("ErrUnhealthy with 1 problem, got: %s", got)
+	}
+	if got := (ErrUnhealthy{Workspace: "/go", Problems: 3}).Error(); got != "3 problems found in /go" {
+		t.Errorf("ErrUnhealthy with 3 problems, got: %s", got)
+	}
+}
+
+func TestErrLocked(t *testing.T) {
+	got := (ErrLocked{Path: "installer.lock", PID: 42, Stale: true}).Error()
+	if !strings.Contains(got, "released once the process holding it exits") || strings.Contains(got, "remove") {
+		t.Errorf("ErrLocked that is stale should say it is released automatically, got: %s", got)
+	}
+}
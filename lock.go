@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// lockPollInterval is how often a held lock is retried while waiting for it
+var lockPollInterval = 100 * time.Millisecond
+
+// errLockHeld is returned by tryLock when another process holds the lock
+var errLockHeld = errors.New("lock is held by another process")
+
+// fileLock is an exclusive advisory lock on a file that records the PID of its owner
+type fileLock struct {
+	path string
+	file *os.File
+}
+
+// workspaceLockPath guards the symlinks, version file and shell profiles of a workspace
+func workspaceLockPath(workspace string) string {
+	return filepath.Join(workspace, "installer.lock")
+}
+
+// versionLockPath guards the download and versions/<version> directory of a single
+// version so that different versions can be installed at the same time
+func versionLockPath(workspace, version string) string {
+	return filepath.Join(workspace, "locks", version+".lock")
+}
+
+// lockFile takes an exclusive lock on path and records the PID of igo in it, waiting
+// up to timeout for the process that holds it to release it
+func lockFile(path string, timeout time.Duration) (*fileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, internal.ErrFile{Path: filepath.Dir(path), Err: err, How: "os.MkdirAll"}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, internal.ErrFile{Path: path, Err: err, How: "os.OpenFile"}
+	}
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		err := tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockHeld) {
+			_ = f.Close()
+			return nil, internal.ErrFile{Path: path, Err: err, How: "flock"}
+		}
+		pid := lockOwner(f)
+		if !time.Now().Before(deadline) {
+			_ = f.Close()
+			return nil, internal.ErrLocked{Path: path, PID: pid, Stale: pid > 0 && !processAlive(pid)}
+		}
+		if !waiting {
+			waiting = true
+			color.Yellow("Waiting up to %v for igo (pid %d) to release %s", timeout, pid, path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+	// a PID left behind belongs to an igo that exited without unlocking, the kernel
+	// released its lock so it is only reported
+	if pid := lockOwner(f); pid > 0 {
+		color.Yellow("Took over stale lock %s of igo pid %d", path, pid)
+	}
+	if err := f.Truncate(0); err != nil {
+		_ = f.Close()
+		return nil, internal.ErrFile{Path: path, Err: err, How: "truncate"}
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		_ = f.Close()
+		return nil, internal.ErrFile{Path: path, Err: err, How: "write"}
+	}
+	return &fileLock{path: path, file: f}, nil
+}
+
+// lockOwner reads the PID recorded in a lock file, or 0 when there is none
+func lockOwner(f *os.File) int {
+	b, err := io.ReadAll(io.NewSectionReader(f, 0, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// unlock clears the PID and releases the lock, the lock file itself is kept since
+// removing it would let two processes lock different files of the same path
+func (l *fileLock) unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	errs := []error{l.file.Truncate(0), unlockFile(l.file), l.file.Close()}
+	l.file = nil
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to unlock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// inspectLock reports the PID recorded in the lock file at path and whether a
+// process holds its lock, without taking it over
+func inspectLock(path string) (pid int, held bool, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return 0, false, internal.ErrFile{Path: path, Err: err, How: "os.OpenFile"}
+	}
+	defer f.Close()
+	pid = lockOwner(f)
+	err = tryLock(f)
+	if errors.Is(err, errLockHeld) {
+		return pid, true, nil
+	} else if err != nil {
+		return pid, false, internal.ErrFile{Path: path, Err: err, How: "flock"}
+	}
+	return pid, false, unlockFile(f)
+}
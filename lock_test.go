@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "1.20.0.lock")
+	lock, err := lockFile(path, 0)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(content))
+
+	// a second open file description of the same path cannot take the lock
+	_, err = lockFile(path, 0)
+	var locked internal.ErrLocked
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, os.Getpid(), locked.PID)
+	assert.False(t, locked.Stale)
+
+	// a waiting lock is acquired once the owner releases it
+	go func() {
+		time.Sleep(3 * lockPollInterval)
+		assert.NoError(t, lock.unlock())
+	}()
+	second, err := lockFile(path, 5*time.Second)
+	require.NoError(t, err)
+	assert.NoError(t, second.unlock())
+
+	// different versions are locked independently
+	workspace := t.TempDir()
+	a, err := lockFile(versionLockPath(workspace, "1.20.0"), 0)
+	require.NoError(t, err)
+	defer a.unlock()
+	b, err := lockFile(versionLockPath(workspace, "1.21.0"), 0)
+	require.NoError(t, err)
+	defer b.unlock()
+}
+
+func TestLockFile_stale(t *testing.T) {
+	// a PID left behind by a killed igo does not block the lock
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	path := filepath.Join(t.TempDir(), "installer.lock")
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0644))
+	lock, err := lockFile(path, 0)
+	require.NoError(t, err)
+	assert.NoError(t, lock.unlock())
+	assert.False(t, processAlive(cmd.Process.Pid))
+	assert.True(t, processAlive(os.Getpid()))
+}
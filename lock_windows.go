@@ -0,0 +1,24 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// tryLock is not supported on windows
+func tryLock(_ *os.File) error {
+	return internal.ErrOSNotSupported{OS: "windows"}
+}
+
+// unlockFile is not supported on windows
+func unlockFile(_ *os.File) error {
+	return internal.ErrOSNotSupported{OS: "windows"}
+}
+
+// processAlive assumes the process exists on windows
+func processAlive(_ int) bool {
+	return true
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// lockFormat is the format of the files that -export-lock writes, -import-lock refuses
+// newer formats
+const lockFormat = 1
+
+// installMetadata is what install records in versions/<version>/install.json
+type installMetadata struct {
+	Version     string    `json:"version"`
+	Tarball     string    `json:"tarball"`
+	SHA256      string    `json:"sha256"`
+	GOOS        string    `json:"goos"`
+	GOARCH      string    `json:"goarch"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// toolchainLock is the file of -export-lock and -import-lock
+type toolchainLock struct {
+	Format   int             `json:"format"`
+	GOOS     string          `json:"goos"`
+	GOARCH   string          `json:"goarch"`
+	Default  string          `json:"default,omitempty"`
+	Versions []lockedVersion `json:"versions"`
+}
+
+// lockedVersion is an installed version of go in a toolchainLock
+type lockedVersion struct {
+	Version     string          `json:"version"`
+	Tarball     string          `json:"tarball"`
+	SHA256      string          `json:"sha256"`
+	InstalledAt time.Time       `json:"installed_at"`
+	Packages    []lockedPackage `json:"packages,omitempty"`
+}
+
+// lockedPackage is a binary in the go/bin of a lockedVersion with the module it was
+// built from
+type lockedPackage struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+// installMetadataPath is the install.json of the version installed in versionDir
+func installMetadataPath(versionDir string) string {
+	return filepath.Join(versionDir, "install.json")
+}
+
+// write saves the metadata into versionDir
+func (metadata installMetadata) write(versionDir string) error {
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := installMetadataPath(versionDir)
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return internal.ErrFile{Path: path, Err: err, How: "os.WriteFile"}
+	}
+	return nil
+}
+
+// readInstallMetadata returns the installMetadata of the version installed in versionDir,
+// versions installed by older releases of igo have none
+func readInstallMetadata(versionDir string) (installMetadata, error) {
+	var metadata installMetadata
+	path := installMetadataPath(versionDir)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return metadata, err
+	}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return metadata, internal.ErrFile{Path: path, Err: err, How: "json.Unmarshal"}
+	}
+	return metadata, nil
+}
+
+// lockedPackages returns the binaries in the go/bin of version that go version -m can
+// trace back to a module, which skips go, gofmt and anything not built by go install
+func lockedPackages(workspace, version string) ([]lockedPackage, error) {
+	binDir := filepath.Join(workspace, "versions", version, "go", "bin")
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return nil, internal.ErrDirEntries{Path: binDir, Err: err}
+	}
+	var pkgs []lockedPackage
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, "go.") || strings.HasPrefix(name, "gofmt.") {
+			continue
+		}
+		info, err := buildInfo(workspace, version, filepath.Join(binDir, name))
+		if err != nil {
+			continue
+		}
+		pkgs = append(pkgs, lockedPackage{Name: name, Path: info.Path, Module: info.Module, Version: info.Version, Sum: info.Sum})
+	}
+	return pkgs, nil
+}
+
+// lockVersion returns the lockedVersion of an installed version, the checksum of a
+// version installed before igo recorded it comes from its cached tarball or go.dev
+func (app *Application) lockVersion(version string) (lockedVersion, error) {
+	workspace := app.Workspace()
+	versionDir := filepath.Join(workspace, "versions", version)
+	locked := lockedVersion{Version: version}
+	if metadata, err := readInstallMetadata(versionDir); err == nil {
+		locked.Tarball, locked.SHA256, locked.InstalledAt = metadata.Tarball, metadata.SHA256, metadata.InstalledAt
+	} else {
+		locked.Tarball = fmt.Sprintf("go%s.%s-%s.tar.gz", version, *app.Figs.String(kGoos), *app.Figs.String(kGoArch))
+		if locked.SHA256, err = sha256File(filepath.Join(workspace, "downloads", locked.Tarball)); err != nil {
+			if locked.SHA256, err = app.checksum(locked.Tarball); err != nil {
+				return locked, fmt.Errorf("go %s has no recorded checksum: %w", version, err)
+			}
+		}
+		installedAt, err := versionInstalledAt(workspace, version)
+		if err != nil {
+			return locked, err
+		}
+		locked.InstalledAt = installedAt.UTC()
+	}
+	pkgs, err := lockedPackages(workspace, version)
+	if err != nil {
+		return locked, err
+	}
+	locked.Packages = pkgs
+	return locked, nil
+}
+
+// exportLock writes the lock file of every installed version of go to the file of
+// -export-lock, or to stdout when it is -
+func exportLock(app *Application) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	workspace := app.Workspace()
+	if _, err := os.Stat(workspace); os.IsNotExist(err) {
+		return internal.ErrNoGoInstalled{Workspace: workspace}
+	}
+	versions, err := installedVersions(workspace)
+	if err != nil {
+		return err
+	}
+	active, _ := app.activatedVersion()
+	lock := toolchainLock{
+		Format:   lockFormat,
+		GOOS:     *app.Figs.String(kGoos),
+		GOARCH:   *app.Figs.String(kGoArch),
+		Default:  active,
+		Versions: []lockedVersion{},
+	}
+	var errs []error
+	for _, version := range versions {
+		if !isInstalled(workspace, version) {
+			continue
+		}
+		locked, err := app.lockVersion(version)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lock.Versions = append(lock.Versions, locked)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	path := *app.Figs.String(cmdExportLock)
+	if path == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return internal.ErrFile{Path: path, Err: err, How: "os.WriteFile"}
+	}
+	color.Green("Locked %d versions of go in %s", len(lock.Versions), path)
+	return nil
+}
+
+// readToolchainLock reads the lock file at path
+func readToolchainLock(path string) (toolchainLock, error) {
+	var lock toolchainLock
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return lock, internal.ErrFile{Path: path, Err: err, How: "os.ReadFile"}
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return lock, internal.ErrFile{Path: path, Err: err, How: "json.Unmarshal"}
+	}
+	if lock.Format > lockFormat {
+		return lock, fmt.Errorf("%s has format %d, upgrade igo to import it", path, lock.Format)
+	}
+	return lock, nil
+}
+
+// importLock installs the versions of go in the lock file of -import-lock from tarballs
+// with the locked checksums, installs the locked module versions of their packages and
+// activates the locked default
+func importLock(app *Application) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	path := *app.Figs.String(cmdImportLock)
+	lock, err := readToolchainLock(path)
+	if err != nil {
+		return err
+	}
+	goos, goarch := *app.Figs.String(kGoos), *app.Figs.String(kGoArch)
+	if lock.GOOS != goos || lock.GOARCH != goarch {
+		return fmt.Errorf("%s locks go for %s/%s, not %s/%s", path, lock.GOOS, lock.GOARCH, goos, goarch)
+	}
+	workspace := app.Workspace()
+	var errs []error
+	var installs []installRecord
+	app.checksums = map[string]string{}
+	for _, locked := range lock.Versions {
+		app.checksums[locked.Tarball] = locked.SHA256
+		versionDir := filepath.Join(workspace, "versions", locked.Version)
+		if !isInstalled(workspace, locked.Version) {
+			installs = append(installs, installRecord{Selector: locked.Version, Version: locked.Version})
+			continue
+		}
+		metadata, err := readInstallMetadata(versionDir)
+		switch {
+		case err != nil:
+			color.Yellow("go %s was installed without a recorded checksum, reinstall it to verify it", locked.Version)
+		case !strings.EqualFold(metadata.SHA256, locked.SHA256):
+			errs = append(errs, fmt.Errorf("go %s: %w", locked.Version,
+				internal.ErrChecksumMismatch{Path: versionDir, Want: locked.SHA256, Got: metadata.SHA256}))
+		default:
+			color.Green("go %s is already installed from %s", locked.Version, locked.Tarball)
+		}
+	}
+	// the lock file has the exact packages of each version instead of -extra-packages
+	app.Figs.StoreBool(kExtras, false)
+	app.installRecords(installs)
+	for _, record := range installs {
+		if record.err != nil {
+			errs = append(errs, fmt.Errorf("go %s: %w", record.Version, record.err))
+		}
+	}
+	for _, locked := range lock.Versions {
+		if !isInstalled(workspace, locked.Version) || len(locked.Packages) == 0 {
+			continue
+		}
+		binDir := filepath.Join(workspace, "versions", locked.Version, "go", "bin")
+		var pkgs []extraPackage
+		for _, pkg := range locked.Packages {
+			info, err := buildInfo(workspace, locked.Version, filepath.Join(binDir, pkg.Name))
+			if err != nil || info.Path != pkg.Path || info.Version != pkg.Version {
+				pkgs = append(pkgs, extraPackage{Name: pkg.Name, Module: pkg.Path, Version: pkg.Version})
+			}
+		}
+		if len(pkgs) > 0 {
+			if err := app.installPackages(app.versionEnvs(locked.Version), locked.Version, pkgs); err != nil {
+				errs = append(errs, fmt.Errorf("go %s: %w", locked.Version, err))
+				continue
+			}
+		}
+		// go install verifies modules against go.sum and the checksum database, which
+		// must agree with the sum that was locked
+		for _, pkg := range locked.Packages {
+			bin := filepath.Join(binDir, pkg.Name)
+			if info, err := buildInfo(workspace, locked.Version, bin); err == nil && len(pkg.Sum) > 0 && info.Sum != pkg.Sum {
+				errs = append(errs, internal.ErrChecksumMismatch{Path: bin, Want: pkg.Sum, Got: info.Sum})
+			}
+		}
+	}
+	if active, _ := app.activatedVersion(); len(lock.Default) > 0 && active != lock.Default {
+		if err := use(app, lock.Default); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	color.Magenta("Imported %d versions of go from %s", len(lock.Versions), path)
+	return nil
+}
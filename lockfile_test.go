@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuildInfo(t *testing.T) {
+	info, ok := parseBuildInfo("/root/go/bin/gopls: go1.22.1\n" +
+		"\tpath\tgolang.org/x/tools/gopls\n" +
+		"\tmod\tgolang.org/x/tools/gopls\tv0.16.0\th1:abc=\n" +
+		"\tdep\tgolang.org/x/mod\tv0.20.0\th1:def=\n")
+	require.True(t, ok)
+	assert.Equal(t, binaryModule{Path: "golang.org/x/tools/gopls", Module: "golang.org/x/tools/gopls", Version: "v0.16.0", Sum: "h1:abc="}, info)
+	_, ok = parseBuildInfo("/root/go/bin/vet: go1.22.1\n\tpath\tcmd/vet\n")
+	assert.False(t, ok)
+}
+
+func TestExportImportLock(t *testing.T) {
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	mirrors, sums := fileMirror(t, "1.20.0", "1.21.0")
+	machine := func(t *testing.T, args ...string) (*Application, string) {
+		home := t.TempDir()
+		UserHomeDir = func() (string, error) { return home, nil }
+		workspace := filepath.Join(home, "go")
+		os.Args = append([]string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64",
+			"-extras=false", "-profiles=false", "-mirrors", mirrors}, args...)
+		return NewApp(), workspace
+	}
+
+	// install records the tarball and checksum of each version
+	app, workspace := machine(t, "-i", "1.21.0,1.20.0")
+	_, err := captureOutput(t, func() error { return run(app) })
+	require.NoError(t, err)
+	metadata, err := readInstallMetadata(filepath.Join(workspace, "versions", "1.21.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "go1.21.0.linux-amd64.tar.gz", metadata.Tarball)
+	assert.Equal(t, sums["1.21.0"], metadata.SHA256)
+
+	path := filepath.Join(t.TempDir(), "igo.lock")
+	app.Figs.StoreString(cmdInstall, "")
+	app.Figs.StoreString(cmdExportLock, path)
+	_, err = captureOutput(t, func() error { return run(app) })
+	require.NoError(t, err)
+	lock, err := readToolchainLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, lockFormat, lock.Format)
+	assert.Equal(t, "1.20.0", lock.Default)
+	require.Len(t, lock.Versions, 2)
+	for _, locked := range lock.Versions {
+		assert.Equal(t, sums[locked.Version], locked.SHA256)
+		assert.Empty(t, locked.Packages)
+	}
+
+	// another machine gets the same versions and default
+	app, workspace = machine(t, "-import-lock", path)
+	_, err = captureOutput(t, func() error { return run(app) })
+	require.NoError(t, err)
+	assert.True(t, isInstalled(workspace, "1.20.0"))
+	assert.True(t, isInstalled(workspace, "1.21.0"))
+	active, err := app.activatedVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.20.0", active)
+
+	// a tarball that differs from the locked one is refused
+	lock.Versions[0].SHA256 = sums["1.21.0"]
+	content, err := json.Marshal(lock)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	app, workspace = machine(t, "-import-lock", path)
+	_, err = captureOutput(t, func() error { return run(app) })
+	assert.ErrorAs(t, err, &internal.ErrChecksumMismatch{})
+	assert.False(t, isInstalled(workspace, lock.Versions[0].Version))
+	assert.True(t, isInstalled(workspace, lock.Versions[1].Version))
+}
@@ -1,10 +1,8 @@
 package main
 
 import (
-	"github.com/andreimerlescu/go-common/version"
+	"flag"
 	"github.com/andreimerlescu/igo/internal"
-	"github.com/fatih/color"
-	"log"
 	"os"
 	"runtime"
 )
@@ -13,59 +11,100 @@ func main() {
 	if runtime.GOOS == "windows" {
 		panic("windows not supported, please use Go's MSI installers instead")
 	}
-	app := NewApp()
+	if isShimInvocation(os.Args[0]) {
+		exit(runShim(os.Args[0], os.Args[1:]))
+	}
+	exit(run(NewApp()))
+}
 
+// run executes the command selected on the command line and returns its error
+func run(app *Application) error {
+	format, err := app.outputFormat()
+	if err != nil {
+		return err
+	}
+	configureColor(format)
 	if *app.Figs.Bool(cmdVersion) {
-		color.Magenta(BinaryVersion() + " - " + internal.About())
-		os.Exit(0)
+		return showVersion(format)
 	}
 	if *app.Figs.Bool(cmdList) {
-		list(app)
-		return
+		return list(app)
+	}
+	if *app.Figs.Bool(cmdRemote) {
+		return remote(app)
+	}
+	if selector := *app.Figs.String(cmdExec); len(selector) > 0 {
+		return execute(app, selector, flag.Args())
 	}
 	if *app.Figs.Bool(cmdEnv) {
-		env(app)
-		return
+		return env(app)
+	}
+	if *app.Figs.Bool(cmdWhich) || *app.Figs.Bool(cmdCurrent) {
+		return which(app, flag.Args())
+	}
+	if *app.Figs.Bool(cmdDoctor) {
+		return doctor(app)
+	}
+	if *app.Figs.Bool(cmdPrune) {
+		return prune(app)
+	}
+	if *app.Figs.Bool(cmdSync) {
+		return syncManifest(app)
+	}
+	if len(*app.Figs.String(cmdExportLock)) > 0 {
+		return exportLock(app)
 	}
-	maybeVersions := map[string]string{
-		"install":   *app.Figs.String(cmdInstall),
-		"uninstall": *app.Figs.String(cmdUninstall),
-		"fix":       *app.Figs.String(cmdFix),
-		"activate":  *app.Figs.String(cmdActivate),
-		"switch":    *app.Figs.String(cmdSwitch),
+	if len(*app.Figs.String(cmdImportLock)) > 0 {
+		return importLock(app)
 	}
-	for command, maybeVersion := range maybeVersions {
+	if selector := *app.Figs.String(cmdPin); len(selector) > 0 || *app.Figs.Bool(kUnset) {
+		return pin(app, selector)
+	}
+	if len(*app.Figs.String(cmdHookEnv)) > 0 {
+		return hookEnv(app)
+	}
+	if len(*app.Figs.String(cmdInit)) > 0 {
+		return shellInit(app)
+	}
+	if app.isBatchInstall() {
+		return installBatch(app)
+	}
+	maybeVersions := []struct {
+		command, version string
+	}{
+		{"install", *app.Figs.String(cmdInstall)},
+		{"uninstall", *app.Figs.String(cmdUninstall)},
+		{"fix", *app.Figs.String(cmdFix)},
+		{"activate", *app.Figs.String(cmdActivate)},
+		{"switch", *app.Figs.String(cmdSwitch)},
+	}
+	for _, maybe := range maybeVersions {
+		command, maybeVersion := maybe.command, maybe.version
 		if len(maybeVersion) == 0 {
 			continue
 		}
-		if err := app.validateVersion(maybeVersion); err != nil {
-			if *app.Figs.Bool(kVerbose) || *app.Figs.Bool(kDebug) {
-				color.Red("ErrBadVersion(%T %s): %w", maybeVersion, maybeVersion, err)
-			}
-			log.Fatalf("ErrBadVersion(%T %s): %s", maybeVersion, maybeVersion, err.Error())
+		var resolved string
+		resolved, err = app.resolveSelector(command, maybeVersion)
+		if err != nil {
+			return internal.ErrBadVersion{Version: maybeVersion, Err: err}
 		}
-		ver := version.FromString(maybeVersion)
-		if ver.String() == "v0.0.1" {
-			log.Fatalf("failed to parse the version: %s", ver.String())
+		maybeVersion = resolved
+		if err := app.validateVersion(maybeVersion); err != nil {
+			return internal.ErrBadVersion{Version: maybeVersion, Err: err}
 		}
 		switch command {
 		case "install":
-			if len(maybeVersion) > 0 {
-				install(app, maybeVersion)
-			}
+			err = install(app, maybeVersion)
 		case "uninstall":
-			if len(maybeVersion) > 0 {
-				uninstall(app, maybeVersion)
-			}
+			err = uninstall(app, maybeVersion)
 		case "fix":
-			if len(maybeVersion) > 0 {
-				fix(app, maybeVersion)
-			}
+			err = fix(app, maybeVersion)
 		default:
-			if len(maybeVersion) > 0 {
-				use(app, maybeVersion)
-			}
+			err = use(app, maybeVersion)
+		}
+		if err != nil {
+			return err
 		}
 	}
-
+	return nil
 }
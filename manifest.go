@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// kinds of driftRecord
+const (
+	driftVersion  = "version"
+	driftDefault  = "default"
+	driftPackage  = "package"
+	driftUnlisted = "unlisted"
+)
+
+// manifest is the toolchain that -sync makes the machine match
+type manifest struct {
+	// Versions are the selectors of the versions of go to install
+	Versions []string `yaml:"versions"`
+	// Default is the selector of the version to activate
+	Default string `yaml:"default"`
+	// ExtraPackages replace -extra-packages and are installed into every listed version,
+	// leaving them out keeps -extra-packages and skips checking the packages
+	ExtraPackages map[string]string `yaml:"extra_packages"`
+	// Mirrors replace -mirrors when they are set
+	Mirrors []string `yaml:"mirrors"`
+}
+
+// driftRecord is a difference between the manifest and the workspace for -format
+type driftRecord struct {
+	Kind string `json:"kind" yaml:"kind"`
+	// Name is the selector of a version, the name of a package or an unlisted version
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Want    string `json:"want" yaml:"want"`
+	Have    string `json:"have" yaml:"have"`
+	Synced  bool   `json:"synced" yaml:"synced"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// loadManifest reads the manifest at path, rejecting keys that it does not know so that
+// a typo is not silently ignored
+func loadManifest(path string) (manifest, error) {
+	var m manifest
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return m, internal.ErrFile{Path: path, Err: err, How: "os.ReadFile"}
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return m, internal.ErrFile{Path: path, Err: err, How: "yaml.Decode"}
+	}
+	if len(m.Versions) == 0 && len(m.Default) == 0 {
+		return m, fmt.Errorf("%s lists no versions of go", path)
+	}
+	return m, nil
+}
+
+// resolveManifestVersion resolves selector against the go.dev release index and falls
+// back to the installed versions when the index cannot be reached
+func (app *Application) resolveManifestVersion(selector string) (string, error) {
+	version, err := app.resolveSelector("install", selector)
+	if err == nil {
+		return version, nil
+	}
+	if installed, installedErr := app.resolveSelector(cmdSync, selector); installedErr == nil {
+		color.Yellow("Resolved %s to the installed go %s: %v", selector, installed, err)
+		return installed, nil
+	}
+	return "", err
+}
+
+// isInstalled reports whether version finished installing into workspace
+func isInstalled(workspace, version string) bool {
+	_, err := os.Stat(filepath.Join(workspace, "versions", version, "installer.lock"))
+	return err == nil
+}
+
+// binaryModule is the package and main module that a binary in go/bin was built from
+type binaryModule struct {
+	Path    string
+	Module  string
+	Version string
+	Sum     string
+}
+
+// parseBuildInfo reads the path and mod lines that go version -m prints for a binary
+func parseBuildInfo(output string) (binaryModule, bool) {
+	var info binaryModule
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "path":
+			info.Path = fields[1]
+		case len(fields) >= 3 && fields[0] == "mod":
+			info.Module, info.Version = fields[1], fields[2]
+			if len(fields) >= 4 {
+				info.Sum = fields[3]
+			}
+		}
+	}
+	return info, len(info.Path) > 0 && len(info.Version) > 0
+}
+
+// buildInfo returns the package and main module that bin was built from using the go
+// of version
+func buildInfo(workspace, version, bin string) (binaryModule, error) {
+	goBinPath := filepath.Join(workspace, "versions", version, "go", "bin", "go."+version)
+	cmd := exec.Command(goBinPath, "version", "-m", bin)
+	cmd.Env = append(os.Environ(), "GOROOT="+filepath.Join(workspace, "versions", version, "go"))
+	output, err := cmd.Output()
+	if err != nil {
+		return binaryModule{}, err
+	}
+	info, ok := parseBuildInfo(string(output))
+	if !ok {
+		return info, fmt.Errorf("%s has no module information", bin)
+	}
+	return info, nil
+}
+
+// packageDrift returns the pkgs that are missing from version or built from another
+// version of their module than the one they are pinned to
+func packageDrift(workspace, version string, pkgs []extraPackage) ([]driftRecord, []extraPackage) {
+	var records []driftRecord
+	var missing []extraPackage
+	for _, pkg := range pkgs {
+		record := driftRecord{Kind: driftPackage, Name: pkg.Name, Version: version, Want: pkg.Version}
+		bin := filepath.Join(workspace, "versions", version, "go", "bin", pkg.Name)
+		if _, err := os.Stat(bin); err != nil {
+			record.Have = "missing"
+		} else if pkg.Version == "latest" {
+			continue
+		} else if have, err := buildInfo(workspace, version, bin); err != nil || have.Version != pkg.Version {
+			record.Have = cmp.Or(have.Version, "unknown")
+		} else {
+			continue
+		}
+		records = append(records, record)
+		missing = append(missing, pkg)
+	}
+	return records, missing
+}
+
+// syncManifest installs the versions and packages of the -manifest that are missing,
+// activates its default version and removes the unlisted versions with -remove-unlisted,
+// or only reports the drift with -dry-run
+func syncManifest(app *Application) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	format, err := app.outputFormat()
+	if err != nil {
+		return err
+	}
+	defer reportOnStderr(format)()
+	path := *app.Figs.String(kManifest)
+	m, err := loadManifest(path)
+	if err != nil {
+		return err
+	}
+	if len(m.Mirrors) > 0 {
+		app.Figs.StoreList(kMirrors, m.Mirrors)
+	}
+	if m.ExtraPackages != nil {
+		app.Figs.StoreMap(kExtraPackages, m.ExtraPackages)
+	}
+	dryRun, removeUnlisted := *app.Figs.Bool(kDryRun), *app.Figs.Bool(kRemoveUnlisted)
+	workspace := app.Workspace()
+	previous, _ := app.activatedVersion()
+	var drift []driftRecord
+	var errs []error
+	// the versions that are missing
+	var desired []string
+	var installs []installRecord
+	for _, selector := range m.Versions {
+		version, err := app.resolveManifestVersion(selector)
+		if err == nil {
+			err = app.validateVersion(version)
+		}
+		if err != nil {
+			err = internal.ErrBadVersion{Version: selector, Err: err}
+			drift = append(drift, driftRecord{Kind: driftVersion, Name: selector, Want: "installed", Have: "unresolved", Error: err.Error()})
+			errs = append(errs, err)
+			continue
+		}
+		if slices.Contains(desired, version) {
+			continue
+		}
+		desired = append(desired, version)
+		if !isInstalled(workspace, version) {
+			installs = append(installs, installRecord{Selector: selector, Version: version})
+		}
+	}
+	defaultVersion := ""
+	if len(m.Default) > 0 {
+		if defaultVersion, err = app.resolveManifestVersion(m.Default); err != nil {
+			err = internal.ErrBadVersion{Version: m.Default, Err: err}
+			drift = append(drift, driftRecord{Kind: driftDefault, Name: m.Default, Want: "activated", Have: "unresolved", Error: err.Error()})
+			errs = append(errs, err)
+		} else if !slices.Contains(desired, defaultVersion) {
+			desired = append(desired, defaultVersion)
+			if !isInstalled(workspace, defaultVersion) {
+				installs = append(installs, installRecord{Selector: m.Default, Version: defaultVersion})
+			}
+		}
+	}
+	if !dryRun {
+		app.installRecords(installs)
+	}
+	for _, record := range installs {
+		item := driftRecord{Kind: driftVersion, Name: record.Selector, Version: record.Version, Want: "installed", Have: "missing",
+			Synced: record.Status == installInstalled || record.Status == installSkipped}
+		if record.err != nil {
+			item.Error = record.err.Error()
+			errs = append(errs, fmt.Errorf("go %s: %w", record.Version, record.err))
+		}
+		drift = append(drift, item)
+	}
+	// every install activated its version, activate the default or else the version
+	// that was activated before
+	activate := defaultVersion
+	if len(activate) == 0 && slices.Contains(desired, previous) {
+		activate = previous
+	}
+	var activateErr error
+	if active, _ := app.activatedVersion(); !dryRun && len(activate) > 0 && active != activate {
+		if activateErr = use(app, activate); activateErr != nil {
+			errs = append(errs, activateErr)
+		}
+	}
+	if len(defaultVersion) > 0 && previous != defaultVersion {
+		item := driftRecord{Kind: driftDefault, Name: m.Default, Version: defaultVersion, Want: "activated",
+			Have: cmp.Or(previous, "none"), Synced: !dryRun && activateErr == nil}
+		if activateErr != nil {
+			item.Error = activateErr.Error()
+		}
+		drift = append(drift, item)
+	}
+	// the packages of every listed version that is installed
+	if m.ExtraPackages != nil {
+		pkgs := extraPackageSpecs(m.ExtraPackages)
+		for _, version := range desired {
+			if !isInstalled(workspace, version) {
+				continue
+			}
+			records, missing := packageDrift(workspace, version, pkgs)
+			if !dryRun && len(missing) > 0 {
+				if err := app.installPackages(app.versionEnvs(version), version, missing); err != nil {
+					errs = append(errs, fmt.Errorf("go %s: %w", version, err))
+				}
+				remaining, _ := packageDrift(workspace, version, pkgs)
+				for i := range records {
+					j := slices.IndexFunc(remaining, func(r driftRecord) bool { return r.Name == records[i].Name })
+					if j < 0 {
+						records[i].Synced = true
+					} else {
+						records[i].Error = fmt.Sprintf("go install left %s", remaining[j].Have)
+					}
+				}
+			}
+			drift = append(drift, records...)
+		}
+	}
+	// the versions that the manifest does not list, the activated version is never removed
+	installed, err := installedVersions(workspace)
+	if err != nil {
+		return err
+	}
+	active, _ := app.activatedVersion()
+	for _, version := range installed {
+		if slices.Contains(desired, version) || version == active {
+			continue
+		}
+		item := driftRecord{Kind: driftUnlisted, Name: version, Version: version, Want: "absent", Have: "installed"}
+		if removeUnlisted && !dryRun {
+			if err := removeVersion(app, version); err != nil {
+				item.Error = err.Error()
+				errs = append(errs, fmt.Errorf("failed to remove go %s: %w", version, err))
+			} else {
+				item.Synced = true
+			}
+		}
+		drift = append(drift, item)
+	}
+	if err := showDrift(format, path, drift, dryRun); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if dryRun {
+		unsynced := 0
+		for _, item := range drift {
+			if item.Kind != driftUnlisted || removeUnlisted {
+				unsynced++
+			}
+		}
+		if unsynced > 0 {
+			return internal.ErrDrift{Manifest: path, Drift: unsynced}
+		}
+	}
+	return nil
+}
+
+// showDrift prints the drift that -sync found and what it did about it
+func showDrift(format, path string, drift []driftRecord, dryRun bool) error {
+	switch format {
+	case formatJSON, formatYAML:
+		if drift == nil {
+			drift = []driftRecord{}
+		}
+		return writeRecords(os.Stdout, format, drift)
+	case formatPlain:
+		for _, item := range drift {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\t%t\t%s\n", item.Kind, item.Name, item.Version, item.Want, item.Have, item.Synced, item.Error)
+		}
+		return nil
+	}
+	if len(drift) == 0 {
+		color.Green("The workspace matches %s", path)
+		return nil
+	}
+	for _, item := range drift {
+		switch {
+		case len(item.Error) > 0:
+			color.Red("Failed to sync %s %s: %s", item.Kind, item.Name, item.Error)
+		case item.Synced && item.Kind == driftVersion:
+			color.Green("Installed go %s for %s", item.Version, item.Name)
+		case item.Synced && item.Kind == driftDefault:
+			color.Green("Activated go %s", item.Version)
+		case item.Synced && item.Kind == driftPackage:
+			color.Green("Installed %s %s into go %s", item.Name, item.Want, item.Version)
+		case item.Synced:
+			color.Green("Removed go %s", item.Version)
+		case item.Kind == driftVersion:
+			color.Yellow("go %s for %s is not installed", item.Version, item.Name)
+		case item.Kind == driftDefault:
+			color.Yellow("go %s is not activated, the activated version is %s", item.Version, item.Have)
+		case item.Kind == driftPackage:
+			color.Yellow("%s in go %s is %s instead of %s", item.Name, item.Version, item.Have, item.Want)
+		default:
+			color.Yellow("go %s is installed but not in %s", item.Version, path)
+		}
+	}
+	if dryRun {
+		color.Magenta("Run igo -%s without -%s to sync the workspace to %s", cmdSync, kDryRun, path)
+	}
+	return nil
+}
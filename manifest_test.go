@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "igo.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`versions: [1.22.x, 1.24.x]
+default: 1.24.x
+extra_packages:
+  gopls: golang.org/x/tools/gopls@v0.16.0
+mirrors:
+  - file:///srv/go/
+`), 0644))
+	m, err := loadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.22.x", "1.24.x"}, m.Versions)
+	assert.Equal(t, "1.24.x", m.Default)
+	assert.Equal(t, map[string]string{"gopls": "golang.org/x/tools/gopls@v0.16.0"}, m.ExtraPackages)
+	assert.Equal(t, []string{"file:///srv/go/"}, m.Mirrors)
+
+	// a misspelled key is an error instead of being ignored
+	require.NoError(t, os.WriteFile(path, []byte("version: [1.22.x]\n"), 0644))
+	_, err = loadManifest(path)
+	assert.ErrorAs(t, err, &internal.ErrFile{})
+}
+
+func TestPackageDrift(t *testing.T) {
+	workspace := t.TempDir()
+	bin := filepath.Join(workspace, "versions", "1.22.1", "go", "bin")
+	require.NoError(t, os.MkdirAll(bin, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "gopls"), nil, 0755))
+	pkgs := extraPackageSpecs(map[string]string{"gopls": "golang.org/x/tools/gopls", "dlv": "github.com/go-delve/delve/cmd/dlv@v1.23.0"})
+	records, missing := packageDrift(workspace, "1.22.1", pkgs)
+	require.Len(t, records, 1)
+	assert.Equal(t, driftRecord{Kind: driftPackage, Name: "dlv", Version: "1.22.1", Want: "v1.23.0", Have: "missing"}, records[0])
+	require.Len(t, missing, 1)
+	assert.Equal(t, "github.com/go-delve/delve/cmd/dlv", missing[0].Module)
+}
+
+func TestSyncManifest(t *testing.T) {
+	home := t.TempDir()
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	UserHomeDir = func() (string, error) { return home, nil }
+	mirrors, _ := fileMirror(t, "1.20.0", "1.21.0")
+	path := filepath.Join(t.TempDir(), "igo.yml")
+	require.NoError(t, os.WriteFile(path, []byte("versions: [1.20.0, 1.21.0]\ndefault: 1.20.0\nmirrors: [\""+mirrors+"\"]\n"), 0644))
+	workspace := filepath.Join(home, "go")
+	unlisted := filepath.Join(workspace, "versions", "1.19.0")
+	require.NoError(t, os.MkdirAll(filepath.Join(unlisted, "go", "bin"), 0755))
+	args := []string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64", "-extras=false",
+		"-profiles=false", "-sync", "-manifest", path, "-remove-unlisted", "-format", formatJSON}
+
+	// -dry-run reports every difference and changes nothing
+	os.Args = append(args, "-dry-run")
+	out, err := captureOutput(t, func() error { return run(NewApp()) })
+	assert.ErrorAs(t, err, &internal.ErrDrift{})
+	assert.Equal(t, exitDrift, exitCode(err))
+	var drift []driftRecord
+	require.NoError(t, json.Unmarshal([]byte(out), &drift))
+	assert.Equal(t, []driftRecord{
+		{Kind: driftVersion, Name: "1.20.0", Version: "1.20.0", Want: "installed", Have: "missing"},
+		{Kind: driftVersion, Name: "1.21.0", Version: "1.21.0", Want: "installed", Have: "missing"},
+		{Kind: driftDefault, Name: "1.20.0", Version: "1.20.0", Want: "activated", Have: "none"},
+		{Kind: driftUnlisted, Name: "1.19.0", Version: "1.19.0", Want: "absent", Have: "installed"},
+	}, drift)
+	assert.NoDirExists(t, filepath.Join(workspace, "versions", "1.20.0"))
+	assert.DirExists(t, unlisted)
+
+	os.Args = args
+	app := NewApp()
+	out, err = captureOutput(t, func() error { return run(app) })
+	require.NoError(t, err)
+	drift = nil
+	require.NoError(t, json.Unmarshal([]byte(out), &drift))
+	require.Len(t, drift, 4)
+	for _, item := range drift {
+		assert.True(t, item.Synced, item)
+	}
+	assert.True(t, isInstalled(workspace, "1.20.0"))
+	assert.True(t, isInstalled(workspace, "1.21.0"))
+	assert.NoDirExists(t, unlisted)
+	active, err := app.activatedVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.20.0", active)
+
+	// the workspace now matches the manifest
+	os.Args = append(args, "-dry-run")
+	out, err = captureOutput(t, func() error { return run(NewApp()) })
+	require.NoError(t, err)
+	assert.JSONEq(t, "[]", out)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sha256Pattern matches a hex encoded SHA-256 checksum
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// publishedChecksumTimeout bounds the lookup a pinned -sha256 is compared against so
+// an unreachable go.dev does not hang an offline install
+var publishedChecksumTimeout = 10 * time.Second
+
+// mirrors returns the -mirrors base URLs in the order they are tried, each ending
+// with a slash, falling back to downloadBaseURL when none are configured
+func (app *Application) mirrors() []string {
+	var mirrors []string
+	if list := app.Figs.List(kMirrors); list != nil {
+		for _, mirror := range *list {
+			mirror = strings.TrimSpace(mirror)
+			if len(mirror) == 0 {
+				continue
+			}
+			if !strings.HasSuffix(mirror, "/") {
+				mirror += "/"
+			}
+			mirrors = append(mirrors, mirror)
+		}
+	}
+	if len(mirrors) == 0 {
+		return []string{downloadBaseURL}
+	}
+	return mirrors
+}
+
+// mirrorFilePath returns the local path of name inside a file:// mirror and false
+// when the mirror is served over http or https
+func mirrorFilePath(mirror, name string) (string, bool) {
+	u, err := url.Parse(mirror)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.Join(filepath.FromSlash(u.Path), name), true
+}
+
+// mirrorHas checks that the mirror serves name with a HEAD request, or a stat for
+// a file:// mirror
+func mirrorHas(mirror, name string) error {
+	if path, ok := mirrorFilePath(mirror, name); ok {
+		_, err := os.Stat(path)
+		return err
+	}
+	resp, err := httpHead(mirror + name)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s%s (status: %d)", mirror, name, resp.StatusCode)
+	}
+	return nil
+}
+
+// mirrorChecksum reads the SHA-256 of name that the mirror publishes as name.sha256,
+// the same layout go.dev and dl.google.com use
+func mirrorChecksum(mirror, name string) (string, error) {
+	var body []byte
+	if path, ok := mirrorFilePath(mirror, name+".sha256"); ok {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		body = b
+	} else {
+		resp, err := httpGet(mirror + name + ".sha256")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s%s.sha256 (status: %d)", mirror, name, resp.StatusCode)
+		}
+		b, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return "", err
+		}
+		body = b
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 || !sha256Pattern.MatchString(fields[0]) {
+		return "", fmt.Errorf("invalid checksum for %s on %s", name, mirror)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// checksum returns the SHA-256 of filename from the go.dev release index, or from
+// the first mirror that publishes a .sha256 file for it when go.dev is unreachable
+func (app *Application) checksum(filename string) (string, error) {
+	sum, err := app.releaseChecksum(filename)
+	if err == nil {
+		return sum, nil
+	}
+	errs := []error{err}
+	for _, mirror := range app.mirrors() {
+		sum, err := mirrorChecksum(mirror, filename)
+		if err == nil {
+			return sum, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", fmt.Errorf("no checksum found for %s: %w", filename, errors.Join(errs...))
+}
+
+// publishedChecksum is app.checksum for at most publishedChecksumTimeout, since a pinned
+// -sha256 is only compared against it, false means none was published in time
+func (app *Application) publishedChecksum(filename string) (string, bool) {
+	type result struct {
+		sum string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		sum, err := app.checksum(filename)
+		done <- result{sum, err}
+	}()
+	select {
+	case r := <-done:
+		return r.sum, r.err == nil
+	case <-time.After(publishedChecksumTimeout):
+		return "", false
+	}
+}
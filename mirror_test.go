@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplication_mirrors(t *testing.T) {
+	os.Args = []string{os.Args[0]}
+	app := NewApp()
+	assert.Equal(t, []string{downloadBaseURL}, app.mirrors())
+
+	os.Args = []string{os.Args[0], "-mirrors", "https://artifactory.example.com/go,file:///srv/go/"}
+	app = NewApp()
+	assert.Equal(t, []string{"https://artifactory.example.com/go/", "file:///srv/go/"}, app.mirrors())
+}
+
+func TestVersion_downloadURL_mirrors(t *testing.T) {
+	content := []byte("mirrored tarball")
+	sum := sha256.Sum256(content)
+	mirrorDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, "go1.20.0.linux-amd64.tar.gz"), content, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, "go1.20.0.linux-amd64.tar.gz.sha256"),
+		[]byte(hex.EncodeToString(sum[:])+"  go1.20.0.linux-amd64.tar.gz\n"), 0644))
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	originalReleaseIndexURL := releaseIndexURL
+	defer func() { releaseIndexURL = originalReleaseIndexURL }()
+	releaseIndexURL = server.URL + "/index"
+
+	os.Args = []string{os.Args[0], "-mirrors", server.URL + "/go," + "file://" + mirrorDir}
+	app := NewApp()
+	downloadsDir := t.TempDir()
+	v := Version{
+		Version:      "1.20.0",
+		DownloadName: "go1.20.0.linux-amd64.tar.gz",
+		TarPath:      filepath.Join(downloadsDir, "go1.20.0.linux-amd64.tar.gz"),
+	}
+
+	// the http mirror does not have the tarball so it falls back to the file:// mirror
+	require.NoError(t, v.downloadURL(app))
+	assert.Equal(t, []string{"/go/go1.20.0.linux-amd64.tar.gz"}, requested)
+	got, err := os.ReadFile(v.TarPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	// go.dev is unreachable so the checksum is read from the file:// mirror
+	require.NoError(t, v.verifyChecksum(app))
+	assert.Equal(t, hex.EncodeToString(sum[:]), v.SHA256)
+}
+
+func TestMirrorChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/go1.20.0.linux-amd64.tar.gz.sha256":
+			_, _ = w.Write(bytes.Repeat([]byte("AB"), 32))
+		case "/go1.20.1.linux-amd64.tar.gz.sha256":
+			_, _ = w.Write([]byte("<html>not a checksum</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	sum, err := mirrorChecksum(server.URL+"/", "go1.20.0.linux-amd64.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, string(bytes.Repeat([]byte("ab"), 32)), sum)
+
+	_, err = mirrorChecksum(server.URL+"/", "go1.20.1.linux-amd64.tar.gz")
+	assert.ErrorContains(t, err, "invalid checksum")
+
+	_, err = mirrorChecksum(server.URL+"/", "go1.20.2.linux-amd64.tar.gz")
+	assert.ErrorContains(t, err, "status: 404")
+}
+
+func TestVersion_verifyChecksum_pinned(t *testing.T) {
+	mirrorsFlag := offlineMirrors(t)
+	mirrorDir := t.TempDir()
+	content := []byte("tarball")
+	sum := sha256.Sum256(content)
+	tarPath := filepath.Join(t.TempDir(), "go1.20.0.linux-amd64.tar.gz")
+	require.NoError(t, os.WriteFile(tarPath, content, 0644))
+	os.Args = []string{os.Args[0], "-mirrors", mirrorsFlag}
+	app := NewApp()
+
+	// nothing is published so the pinned checksum is used
+	v := Version{Version: "1.20.0", DownloadName: filepath.Base(tarPath), TarPath: tarPath, SHA256: hex.EncodeToString(sum[:])}
+	require.NoError(t, v.verifyChecksum(app))
+
+	// a pinned checksum that disagrees with the published one fails before the tarball is hashed
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, v.DownloadName+".sha256"), []byte(hex.EncodeToString(sum[:])+"\n"), 0644))
+	app.Figs.StoreList(kMirrors, []string{"file://" + mirrorDir})
+	v.SHA256 = string(bytes.Repeat([]byte("ab"), 32))
+	err := v.verifyChecksum(app)
+	var mismatch internal.ErrChecksumMismatch
+	require.ErrorAs(t, err, &mismatch)
+	assert.True(t, mismatch.Pinned)
+	assert.FileExists(t, tarPath)
+	v.SHA256 = ""
+	require.NoError(t, v.verifyChecksum(app))
+	assert.Equal(t, hex.EncodeToString(sum[:]), v.SHA256)
+
+	// a published checksum that never arrives does not hold up the pinned one
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	originalTimeout := publishedChecksumTimeout
+	defer func() { publishedChecksumTimeout = originalTimeout }()
+	publishedChecksumTimeout = 50 * time.Millisecond
+	app.Figs.StoreList(kMirrors, []string{server.URL})
+	require.NoError(t, v.verifyChecksum(app))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// projectDir returns the nearest directory at or above dir that has a .go_version,
+// go.work or go.mod file, or dir itself when there is none
+func projectDir(dir string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		for _, name := range []string{".go_version", "go.work", "go.mod"} {
+			if _, err := os.Stat(filepath.Join(current, name)); err == nil {
+				return current
+			}
+		}
+		if filepath.Dir(current) == current {
+			return dir
+		}
+	}
+}
+
+// resolvePin resolves selector against the installed versions and then against the
+// go.dev release index, installed reports whether the version is already installed
+func (app *Application) resolvePin(selector string) (version string, installed bool, err error) {
+	if version, err := app.resolveSelector("pin", selector); err == nil {
+		if _, err := os.Stat(filepath.Join(app.Workspace(), "versions", version)); err == nil {
+			return version, true, nil
+		}
+	}
+	version, err = app.resolveSelector("install", selector)
+	return version, false, err
+}
+
+// setToolchain replaces the toolchain directive of the go.mod at path with version,
+// adding it after the go directive when there is none, or removes it when version is
+// empty
+func setToolchain(path, version string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, internal.ErrFile{Path: path, Err: err, How: "os.Stat"}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, internal.ErrFile{Path: path, Err: err, How: "os.ReadFile"}
+	}
+	lines := strings.Split(string(content), "\n")
+	directive := func(name string) int {
+		return slices.IndexFunc(lines, func(line string) bool {
+			fields := strings.Fields(line)
+			return len(fields) >= 2 && fields[0] == name
+		})
+	}
+	toolchain := "toolchain go" + version
+	switch i := directive("toolchain"); {
+	case i >= 0 && len(version) == 0:
+		lines = slices.Delete(lines, i, i+1)
+	case i >= 0 && strings.TrimSpace(lines[i]) == toolchain:
+		return false, nil
+	case i >= 0:
+		lines[i] = toolchain
+	case len(version) == 0:
+		return false, nil
+	default:
+		goLine := directive("go")
+		if goLine < 0 {
+			return false, fmt.Errorf("%s has no go directive", path)
+		}
+		// go mod edit -toolchain puts the directive right after the go directive too
+		lines = slices.Insert(lines, goLine+1, toolchain)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+		return false, internal.ErrFile{Path: path, Err: err, How: "os.WriteFile"}
+	}
+	return true, nil
+}
+
+// confirm asks question on the terminal and returns false without asking when stdin
+// is not a terminal
+func confirm(question string) bool {
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return false
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// hasGoMod returns true when the go.mod at path exists, -toolchain leaves a project
+// without one alone rather than failing after its .go_version was changed
+func hasGoMod(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
+
+// pin writes the version of go that selector resolves to into the .go_version of the
+// current project, or removes it with -unset, and updates the toolchain directive of
+// its go.mod with -toolchain
+func pin(app *Application, selector string) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	dir := projectDir(cwd)
+	goVersionFile := filepath.Join(dir, ".go_version")
+	goMod := filepath.Join(dir, "go.mod")
+	toolchain := *app.Figs.Bool(kToolchain)
+	if *app.Figs.Bool(kUnset) {
+		if err := os.Remove(goVersionFile); os.IsNotExist(err) {
+			color.Yellow("%s is not pinned", dir)
+		} else if err != nil {
+			return internal.ErrFile{Path: goVersionFile, Err: err, How: "os.Remove"}
+		} else {
+			color.Green("Removed %s", goVersionFile)
+		}
+		if toolchain && !hasGoMod(goMod) {
+			color.Yellow("%s has no go.mod, its toolchain directive is left alone", dir)
+		} else if toolchain {
+			if changed, err := setToolchain(goMod, ""); err != nil {
+				return err
+			} else if changed {
+				color.Green("Removed the toolchain directive from %s", goMod)
+			}
+		}
+		return showEffectiveVersion(app, cwd)
+	}
+	version, installed, err := app.resolvePin(selector)
+	if err != nil {
+		return internal.ErrBadVersion{Version: selector, Err: err}
+	}
+	if err := os.WriteFile(goVersionFile, []byte(version+"\n"), 0644); err != nil {
+		return internal.ErrFile{Path: goVersionFile, Err: err, How: "os.WriteFile"}
+	}
+	color.Green("Pinned %s to go %s in %s", dir, version, goVersionFile)
+	if minimum, _, err := goModDirective(goMod, "go"); err == nil && newerThan(minimum, version) {
+		color.Yellow("%s requires go %s which is newer than the pinned go %s", goMod, minimum, version)
+	}
+	if toolchain && !hasGoMod(goMod) {
+		color.Yellow("%s has no go.mod, its toolchain directive is left alone", dir)
+	} else if toolchain {
+		changed, err := setToolchain(goMod, version)
+		if err != nil {
+			return err
+		}
+		if changed {
+			color.Green("Set the toolchain directive of %s to go%s", goMod, version)
+		}
+	}
+	if !installed {
+		if *app.Figs.Bool(kAutoInstall) || confirm(fmt.Sprintf("go %s is not installed, install it now?", version)) {
+			if err := app.validateVersion(version); err != nil {
+				return internal.ErrBadVersion{Version: version, Err: err}
+			}
+			if err := install(app, version); err != nil {
+				return err
+			}
+		} else {
+			color.Yellow("go %s is not installed, run: igo -%s %s", version, cmdInstall, version)
+		}
+	}
+	return showEffectiveVersion(app, cwd)
+}
+
+// showEffectiveVersion prints the version of go that the shims run in dir and the
+// file that decided it
+func showEffectiveVersion(app *Application, dir string) error {
+	source, err := resolveDirVersion(app.Workspace(), dir)
+	if err != nil {
+		color.Yellow("No version of go applies to %s: %v", dir, err)
+		return nil
+	}
+	color.Magenta("Effective version: go %s from %s", source.Version, source)
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetToolchain(t *testing.T) {
+	goMod := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(goMod, []byte("module example.com/app\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n"), 0600))
+
+	changed, err := setToolchain(goMod, "")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = setToolchain(goMod, "1.21.3")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err := os.ReadFile(goMod)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/app\n\ngo 1.21\ntoolchain go1.21.3\n\nrequire example.com/dep v1.0.0\n", string(content))
+
+	changed, err = setToolchain(goMod, "1.21.3")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = setToolchain(goMod, "1.22.0")
+	require.NoError(t, err)
+	content, err = os.ReadFile(goMod)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "go 1.21\ntoolchain go1.22.0\n")
+
+	changed, err = setToolchain(goMod, "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err = os.ReadFile(goMod)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/app\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n", string(content))
+	info, err := os.Stat(goMod)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = setToolchain(filepath.Join(t.TempDir(), "go.mod"), "1.22.0")
+	assert.Error(t, err)
+}
+
+func TestPin(t *testing.T) {
+	t.Setenv(envVersion, "")
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Setenv("GOWORK", "")
+	workspace := fakeWorkspace(t)
+	project := t.TempDir()
+	goMod := filepath.Join(project, "go.mod")
+	require.NoError(t, os.WriteFile(goMod, []byte("module example.com/app\n\ngo 1.21\n"), 0644))
+	sub := filepath.Join(project, "cmd", "app")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	t.Chdir(sub)
+	assert.Equal(t, project, projectDir(sub))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-pin", "1.21", "-toolchain"}
+	require.NoError(t, pin(NewApp(), "1.21"))
+	content, err := os.ReadFile(filepath.Join(project, ".go_version"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.21.0\n", string(content))
+	content, err = os.ReadFile(goMod)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/app\n\ngo 1.21\ntoolchain go1.21.0\n", string(content))
+	source, err := resolveDirVersion(workspace, sub)
+	require.NoError(t, err)
+	assert.Equal(t, "1.21.0", source.Version)
+	assert.Equal(t, filepath.Join(project, ".go_version"), source.Path)
+
+	// a version that is not installed is pinned and left for the shims to install
+	os.Args = []string{os.Args[0], "-godir", workspace, "-pin", "1.23.4"}
+	require.NoError(t, pin(NewApp(), "1.23.4"))
+	content, err = os.ReadFile(filepath.Join(project, ".go_version"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.23.4\n", string(content))
+	assert.NoDirExists(t, filepath.Join(workspace, "versions", "1.23.4"))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-unset", "-toolchain"}
+	require.NoError(t, pin(NewApp(), ""))
+	assert.NoFileExists(t, filepath.Join(project, ".go_version"))
+	content, err = os.ReadFile(goMod)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/app\n\ngo 1.21\n", string(content))
+	source, err = resolveDirVersion(workspace, sub)
+	require.NoError(t, err)
+	assert.Equal(t, goMod, source.Path)
+}
+
+func TestPin_toolchainWithoutGoMod(t *testing.T) {
+	t.Setenv(envVersion, "")
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Setenv("GOWORK", "")
+	workspace := fakeWorkspace(t)
+	project := t.TempDir()
+	t.Chdir(project)
+
+	// -toolchain skips the go.mod that is not there and still pins the version
+	os.Args = []string{os.Args[0], "-godir", workspace, "-pin", "1.21", "-toolchain"}
+	require.NoError(t, pin(NewApp(), "1.21"))
+	content, err := os.ReadFile(filepath.Join(project, ".go_version"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.21.0\n", string(content))
+	assert.NoFileExists(t, filepath.Join(project, "go.mod"))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-unset", "-toolchain"}
+	require.NoError(t, pin(NewApp(), ""))
+	assert.NoFileExists(t, filepath.Join(project, ".go_version"))
+	assert.NoFileExists(t, filepath.Join(project, "go.mod"))
+}
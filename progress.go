@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// progressBarWidth is the number of cells in the download progress bar
+const progressBarWidth = 30
+
+// progressLines makes every progressWriter print lines instead of redrawing a bar,
+// which installBatch sets since bars of parallel downloads overwrite each other
+var progressLines = false
+
+// progressWriter reports the progress of a download as it is written. On a terminal
+// it redraws a bar in place, otherwise it prints a line every 25 percent.
+type progressWriter struct {
+	out     io.Writer
+	name    string
+	tty     bool
+	total   int64
+	written int64
+	resumed int64
+	start   time.Time
+	drawn   time.Time
+	step    int64
+}
+
+// newProgressWriter reports on color.Output the download of name which already has
+// resumed bytes on disk out of total, where total is -1 when it is unknown
+func newProgressWriter(name string, resumed, total int64) *progressWriter {
+	return &progressWriter{
+		out:     color.Output,
+		name:    name,
+		tty:     !progressLines && (isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())),
+		total:   total,
+		written: resumed,
+		resumed: resumed,
+		start:   time.Now(),
+	}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.tty {
+		if time.Since(p.drawn) >= 100*time.Millisecond {
+			p.drawn = time.Now()
+			_, _ = fmt.Fprintf(p.out, "\r%s", p.line())
+		}
+	} else if p.total > 0 {
+		if step := p.written * 4 / p.total; step > p.step {
+			p.step = step
+			_, _ = fmt.Fprintln(p.out, p.line())
+		}
+	}
+	return len(b), nil
+}
+
+// finish draws the final state of the progress bar and ends its line
+func (p *progressWriter) finish() {
+	if p.tty {
+		_, _ = fmt.Fprintf(p.out, "\r%s\n", p.line())
+	}
+}
+
+// line renders the bar, percentage, size and throughput of the download
+func (p *progressWriter) line() string {
+	elapsed := time.Since(p.start).Seconds()
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.written-p.resumed) / elapsed
+	}
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %s %s/s", p.name, humanBytes(p.written), humanBytes(int64(rate)))
+	}
+	filled := int(p.written * progressBarWidth / p.total)
+	filled = min(filled, progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	return fmt.Sprintf("%s [%s] %3d%% %s/%s %s/s", p.name, bar, p.written*100/p.total,
+		humanBytes(p.written), humanBytes(p.total), humanBytes(int64(rate)))
+}
+
+// humanBytes formats a number of bytes using binary units
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanBytes(t *testing.T) {
+	assert.Equal(t, "512 B", humanBytes(512))
+	assert.Equal(t, "1.5 KiB", humanBytes(1536))
+	assert.Equal(t, "70.0 MiB", humanBytes(70*1024*1024))
+}
+
+func TestProgressWriter_nonTTY(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgressWriter("go1.24.3.linux-amd64.tar.gz", 0, 100)
+	p.out, p.tty = &out, false
+	for i := 0; i < 10; i++ {
+		_, err := p.Write(make([]byte, 10))
+		assert.NoError(t, err)
+	}
+	p.finish()
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.NotContains(t, out.String(), "\r")
+	assert.Contains(t, lines[3], "100%")
+}
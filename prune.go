@@ -0,0 +1,304 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// kinds of pruneItem
+const (
+	pruneVersion  = "version"
+	pruneDownload = "download"
+	pruneCache    = "cache"
+)
+
+// cacheTrimAge is how long an entry of GOCACHE can go unused before -prune-cache
+// removes it, the same age that go uses when it trims its own cache
+const cacheTrimAge = 5 * 24 * time.Hour
+
+// downloadNamePattern matches the tarballs and partial downloads in downloads/ and
+// captures their version
+var downloadNamePattern = regexp.MustCompile(`^go(.+)\.[a-z0-9]+-[a-z0-9]+\.tar\.gz(?:\.part)?$`)
+
+// pruneItem is something -prune removes along with the disk space that removing it
+// reclaims
+type pruneItem struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	Path    string `json:"path" yaml:"path"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Reason  string `json:"reason" yaml:"reason"`
+	Size    int64  `json:"size" yaml:"size"`
+	Removed bool   `json:"removed" yaml:"removed"`
+	// files are the entries of GOCACHE to remove when Kind is pruneCache
+	files []string
+}
+
+// prunePlan returns everything that the -prune policies select, the activated
+// version of go is never selected
+func (app *Application) prunePlan(now time.Time) ([]pruneItem, error) {
+	keepPatches := *app.Figs.Int(kKeepPatches)
+	unusedDays := *app.Figs.Int(kUnusedDays)
+	pruneDownloads := *app.Figs.Bool(kPruneDownloads)
+	pruneCaches := *app.Figs.Bool(kPruneCache)
+	if keepPatches <= 0 && unusedDays <= 0 && !pruneDownloads && !pruneCaches {
+		return nil, internal.ErrUsage{Usage: fmt.Sprintf("-%s [-%s] -%s N | -%s N | -%s | -%s",
+			cmdPrune, kDryRun, kKeepPatches, kUnusedDays, kPruneDownloads, kPruneCache)}
+	}
+	workspace := app.Workspace()
+	items := []pruneItem{}
+	versions, err := installedVersions(workspace)
+	if err != nil {
+		return nil, err
+	}
+	active, _ := app.activatedVersion()
+	reasons := map[string]string{}
+	if keepPatches > 0 {
+		for version, reason := range patchesBeyond(versions, keepPatches) {
+			reasons[version] = reason
+		}
+	}
+	if unusedDays > 0 {
+		cutoff := now.AddDate(0, 0, -unusedDays)
+		usage := usageOrEmpty(workspace)
+		for _, version := range versions {
+			if _, ok := reasons[version]; ok {
+				continue
+			}
+			lastUsed, err := versionLastUsed(workspace, version, usage)
+			if err != nil || lastUsed.After(cutoff) {
+				continue
+			}
+			// a version that never ran after it was installed is only as old as its install
+			if used, ok := usage[version]; ok && used.LastUsed.Equal(lastUsed) {
+				reasons[version] = fmt.Sprintf("unused since %s", lastUsed.Format(time.DateOnly))
+			} else {
+				reasons[version] = fmt.Sprintf("installed on %s", lastUsed.Format(time.DateOnly))
+			}
+		}
+	}
+	for _, version := range versions {
+		reason, ok := reasons[version]
+		if !ok || version == active {
+			continue
+		}
+		path := filepath.Join(workspace, "versions", version)
+		size, err := dirSize(path)
+		if err != nil {
+			return nil, internal.ErrFile{Path: path, Err: err, How: "size"}
+		}
+		items = append(items, pruneItem{Kind: pruneVersion, Path: path, Version: version, Reason: reason, Size: size})
+	}
+	if pruneDownloads {
+		downloads, err := pruneDownloadItems(filepath.Join(workspace, "downloads"))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, downloads...)
+	}
+	if pruneCaches {
+		cache, err := pruneCacheItem(filepath.Join(workspace, "cache"), now.Add(-cacheTrimAge))
+		if err != nil {
+			return nil, err
+		}
+		if cache.Size > 0 {
+			items = append(items, cache)
+		}
+	}
+	return items, nil
+}
+
+// patchesBeyond returns the versions that are older than the newest keep patch
+// releases of their minor version, with the reason they are selected
+func patchesBeyond(versions []string, keep int) map[string]string {
+	minors := map[string][]string{}
+	for _, version := range versions {
+		v, ok := parseGoVersion(version)
+		if !ok {
+			continue
+		}
+		minor := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+		minors[minor] = append(minors[minor], version)
+	}
+	selected := map[string]string{}
+	for minor, patches := range minors {
+		sortGoVersions(patches)
+		slices.Reverse(patches)
+		for _, version := range patches[min(keep, len(patches)):] {
+			selected[version] = fmt.Sprintf("older than the newest %d of go %s", keep, minor)
+		}
+	}
+	return selected
+}
+
+// versionLastUsed returns when version last ran through a shim or -exec, or when it
+// was installed if that is more recent
+func versionLastUsed(workspace, version string, usage map[string]usageRecord) (time.Time, error) {
+	lastUsed, err := versionInstalledAt(workspace, version)
+	if err != nil {
+		return lastUsed, err
+	}
+	if used, ok := usage[version]; ok && used.LastUsed.After(lastUsed) {
+		lastUsed = used.LastUsed
+	}
+	return lastUsed, nil
+}
+
+// pruneDownloadItems returns the tarballs and partial downloads in dir
+func pruneDownloadItems(dir string) ([]pruneItem, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, internal.ErrDirEntries{Path: dir, Err: err}
+	}
+	var items []pruneItem
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, internal.ErrFile{Path: filepath.Join(dir, entry.Name()), Err: err, How: "Info"}
+		}
+		item := pruneItem{Kind: pruneDownload, Path: filepath.Join(dir, entry.Name()), Reason: "downloaded tarball", Size: info.Size()}
+		if m := downloadNamePattern.FindStringSubmatch(entry.Name()); m != nil {
+			item.Version = m[1]
+		}
+		if strings.HasSuffix(entry.Name(), ".part") {
+			item.Reason = "partial download"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// pruneCacheItem collects the files in GOCACHE that have not been used since cutoff,
+// go updates the modification time of the entries that it reads
+func pruneCacheItem(dir string, cutoff time.Time) (pruneItem, error) {
+	item := pruneItem{Kind: pruneCache, Path: dir}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		// go keeps its own bookkeeping in these files at the root of the cache
+		if !d.Type().IsRegular() || filepath.Dir(path) == dir {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			item.files = append(item.files, path)
+			item.Size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return item, internal.ErrFile{Path: dir, Err: err, How: "filepath.WalkDir"}
+	}
+	item.Reason = fmt.Sprintf("%d entries unused for %s", len(item.files), cacheTrimAge)
+	return item, nil
+}
+
+// remove deletes the item, a version or download while holding the lock of its
+// version, the cache entries without a lock since go rebuilds any that it misses
+func (item *pruneItem) remove(app *Application) error {
+	switch item.Kind {
+	case pruneVersion:
+		return removeVersion(app, item.Version)
+	case pruneCache:
+		var errs []error
+		for _, file := range item.files {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if len(item.Version) > 0 {
+		lock, err := lockFile(versionLockPath(app.Workspace(), item.Version), *app.Figs.Duration(kLockTimeout))
+		if err != nil {
+			return err
+		}
+		defer func() { internal.Discard(lock.unlock()) }()
+	}
+	if err := os.Remove(item.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// prune removes the versions, downloads and cache entries selected by the -prune
+// policies, or only reports them with -dry-run
+func prune(app *Application) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	workspace := app.Workspace()
+	if _, err := os.Stat(workspace); os.IsNotExist(err) {
+		return internal.ErrNoGoInstalled{Workspace: workspace}
+	}
+	format, err := app.outputFormat()
+	if err != nil {
+		return err
+	}
+	items, err := app.prunePlan(time.Now())
+	if err != nil {
+		return err
+	}
+	dryRun := *app.Figs.Bool(kDryRun)
+	var errs []error
+	var total int64
+	for i := range items {
+		if !dryRun {
+			if err := items[i].remove(app); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", items[i].Path, err))
+				continue
+			}
+			items[i].Removed = true
+		}
+		total += items[i].Size
+	}
+	switch format {
+	case formatJSON, formatYAML:
+		if err := writeRecords(os.Stdout, format, items); err != nil {
+			return err
+		}
+	case formatPlain:
+		for _, item := range items {
+			fmt.Printf("%s\t%s\t%d\t%s\t%t\n", item.Kind, item.Path, item.Size, item.Reason, item.Removed)
+		}
+	default:
+		for _, item := range items {
+			switch {
+			case item.Removed:
+				color.Green("Removed %s %s (%s): %s", item.Kind, item.Path, humanBytes(item.Size), item.Reason)
+			case dryRun:
+				color.Yellow("Would remove %s %s (%s): %s", item.Kind, item.Path, humanBytes(item.Size), item.Reason)
+			}
+		}
+		if dryRun {
+			color.Magenta("Reclaimable: %s", humanBytes(total))
+		} else {
+			color.Magenta("Reclaimed: %s", humanBytes(total))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchesBeyond(t *testing.T) {
+	got := patchesBeyond([]string{"1.21.0", "1.21.2", "1.21.1", "1.22.0", "1.23rc1", "1.23.0"}, 1)
+	assert.Len(t, got, 3)
+	assert.Contains(t, got, "1.21.0")
+	assert.Contains(t, got, "1.21.1")
+	assert.Contains(t, got, "1.23rc1")
+	assert.Empty(t, patchesBeyond([]string{"1.21.0", "1.21.1"}, 2))
+}
+
+func TestPrune(t *testing.T) {
+	workspace := t.TempDir()
+	old := time.Now().AddDate(0, 0, -120)
+	for _, version := range []string{"1.21.0", "1.21.1", "1.22.0", "1.22.1"} {
+		bin := filepath.Join(workspace, "versions", version, "go", "bin")
+		require.NoError(t, os.MkdirAll(bin, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "go."+version), []byte("1234"), 0755))
+	}
+	require.NoError(t, os.Chtimes(filepath.Join(workspace, "versions", "1.22.0"), old, old))
+	require.NoError(t, os.Chtimes(filepath.Join(workspace, "versions", "1.21.0"), old, old))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "version"), []byte("1.21.0"), 0644))
+	downloads := filepath.Join(workspace, "downloads")
+	require.NoError(t, os.MkdirAll(downloads, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloads, "go1.22.0.linux-amd64.tar.gz"), []byte("12345678"), 0644))
+	cache := filepath.Join(workspace, "cache")
+	require.NoError(t, os.MkdirAll(filepath.Join(cache, "ab"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "README"), []byte("go cache"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "ab", "stale-a"), []byte("stale"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "ab", "fresh-a"), []byte("fresh"), 0644))
+	require.NoError(t, os.Chtimes(filepath.Join(cache, "ab", "stale-a"), old, old))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-prune"}
+	_, err := NewApp().prunePlan(time.Now())
+	assert.ErrorAs(t, err, &internal.ErrUsage{})
+
+	args := []string{"-godir", workspace, "-prune", "-keep-patches", "1", "-unused-days", "90", "-prune-downloads", "-prune-cache", "-format", "json"}
+	os.Args = append([]string{os.Args[0]}, append(args, "-dry-run")...)
+	app := NewApp()
+	var items []pruneItem
+	require.NoError(t, json.Unmarshal([]byte(captureStdout(t, func() error { return prune(app) })), &items))
+	kinds := map[string]pruneItem{}
+	for _, item := range items {
+		assert.False(t, item.Removed)
+		kinds[item.Kind+":"+item.Version] = item
+	}
+	assert.Len(t, items, 3)
+	assert.Contains(t, kinds, "version:1.22.0")
+	assert.Equal(t, int64(8), kinds["download:1.22.0"].Size)
+	assert.Equal(t, int64(5), kinds["cache:"].Size)
+	assert.DirExists(t, filepath.Join(workspace, "versions", "1.22.0"))
+
+	// -unused-days tells a version that never ran apart from one that stopped running
+	os.Args = []string{os.Args[0], "-godir", workspace, "-prune", "-unused-days", "90"}
+	plan, err := NewApp().prunePlan(time.Now())
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	assert.Equal(t, "installed on "+old.Format(time.DateOnly), plan[0].Reason)
+	require.NoError(t, recordUsage(workspace, "1.22.0", workspace, old.AddDate(0, 0, 10)))
+	plan, err = NewApp().prunePlan(time.Now())
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	assert.Equal(t, "unused since "+old.AddDate(0, 0, 10).Format(time.DateOnly), plan[0].Reason)
+
+	os.Args = append([]string{os.Args[0]}, args...)
+	app = NewApp()
+	items = nil
+	require.NoError(t, json.Unmarshal([]byte(captureStdout(t, func() error { return prune(app) })), &items))
+	for _, item := range items {
+		assert.True(t, item.Removed)
+	}
+	assert.NoDirExists(t, filepath.Join(workspace, "versions", "1.22.0"))
+	assert.DirExists(t, filepath.Join(workspace, "versions", "1.21.0"), "the activated version is kept")
+	assert.DirExists(t, filepath.Join(workspace, "versions", "1.21.1"))
+	assert.DirExists(t, filepath.Join(workspace, "versions", "1.22.1"))
+	assert.NoFileExists(t, filepath.Join(downloads, "go1.22.0.linux-amd64.tar.gz"))
+	assert.NoFileExists(t, filepath.Join(cache, "ab", "stale-a"))
+	assert.FileExists(t, filepath.Join(cache, "ab", "fresh-a"))
+	assert.FileExists(t, filepath.Join(cache, "README"))
+}
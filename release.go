@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// releaseIndexURL is the go.dev release metadata that lists every published
+// version of Go along with the checksums of its files
+var releaseIndexURL = "https://go.dev/dl/?mode=json&include=all"
+
+// vulnDBURL is the Go vulnerability database, its index/modules.json lists the
+// vulnerabilities of each module and ID/<id>.json has the versions that fix one
+var vulnDBURL = "https://vuln.go.dev"
+
+// vulnFetchJobs is how many entries of the vulnerability database are fetched at once
+const vulnFetchJobs = 8
+
+// Release is an entry of the go.dev release index
+type Release struct {
+	// Version is the name of the release in the go1.X.Y format
+	Version string `json:"version"`
+	// Stable is true when go.dev considers the release stable
+	Stable bool `json:"stable"`
+	// Files are the downloadable artifacts of the release
+	Files []ReleaseFile `json:"files"`
+}
+
+// ReleaseFile is a downloadable artifact of a Release
+type ReleaseFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
+}
+
+// fetchReleases downloads the go.dev release index once per Application
+func (app *Application) fetchReleases() ([]Release, error) {
+	app.releasesMu.Lock()
+	defer app.releasesMu.Unlock()
+	if app.releases != nil {
+		return app.releases, nil
+	}
+	resp, err := httpGet(releaseIndexURL)
+	if err != nil {
+		return nil, internal.ErrDownload{Name: releaseIndexURL, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, internal.ErrDownload{Name: releaseIndexURL, Err: fmt.Errorf("HTTP status %d", resp.StatusCode)}
+	}
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode release index: %w", err)
+	}
+	app.releases = releases
+	return releases, nil
+}
+
+// releaseChecksum returns the published SHA-256 of the filename in the go.dev release index
+func (app *Application) releaseChecksum(filename string) (string, error) {
+	releases, err := app.fetchReleases()
+	if err != nil {
+		return "", err
+	}
+	for _, release := range releases {
+		for _, file := range release.Files {
+			if file.Filename == filename && len(file.SHA256) > 0 {
+				return file.SHA256, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no checksum published for %s", filename)
+}
+
+// hasArchive returns true when the release publishes an archive for goos and goarch
+func (r Release) hasArchive(goos, goarch string) bool {
+	return slices.ContainsFunc(r.Files, func(file ReleaseFile) bool {
+		return file.Kind == "archive" && file.OS == goos && file.Arch == goarch
+	})
+}
+
+// availableReleases returns the versions that publish an archive for goos and goarch
+// from newest to oldest, skipping anything older than the minimum supported 1.16
+func availableReleases(releases []Release, goos, goarch string) []goVersion {
+	var versions []goVersion
+	for _, release := range releases {
+		v, ok := parseGoVersion(release.Version)
+		if !ok || (v.Major == 1 && v.Minor < 16) || !release.hasArchive(goos, goarch) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, func(a, b goVersion) int { return b.Compare(a) })
+	return versions
+}
+
+// supportedMinors returns the two newest minor lines of Go which receive
+// security and bug fixes according to the Go release policy
+func supportedMinors(versions []goVersion) map[[2]int]bool {
+	supported := make(map[[2]int]bool)
+	for _, v := range versions {
+		if len(supported) == 2 {
+			break
+		}
+		if v.IsPreRelease() {
+			continue
+		}
+		supported[[2]int{v.Major, v.Minor}] = true
+	}
+	return supported
+}
+
+// fetchSecurityReleases returns the versions of Go that fix a standard library or
+// toolchain vulnerability, read from the entry of each vulnerability since the index
+// only has the newest fix and not the ones backported to the older supported minor
+func (app *Application) fetchSecurityReleases() (map[string]bool, error) {
+	var modules []struct {
+		Path  string `json:"path"`
+		Vulns []struct {
+			ID    string `json:"id"`
+			Fixed string `json:"fixed"`
+		} `json:"vulns"`
+	}
+	if err := fetchVulnJSON(vulnDBURL+"/index/modules.json", &modules); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, module := range modules {
+		if module.Path != "stdlib" && module.Path != "toolchain" {
+			continue
+		}
+		for _, vuln := range module.Vulns {
+			// -remote lists nothing older than 1.16, which every earlier fix predates
+			if v, ok := parseGoVersion(strings.TrimPrefix(vuln.Fixed, "v")); ok && v.Major == 1 && v.Minor < 16 {
+				continue
+			}
+			ids = append(ids, vuln.ID)
+		}
+	}
+	security := make(map[string]bool)
+	var mu sync.Mutex
+	var errs []error
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for range min(vulnFetchJobs, len(ids)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				fixed, err := fetchVulnFixes(id)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				for _, version := range fixed {
+					security[version] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+	return security, errors.Join(errs...)
+}
+
+// fetchVulnFixes returns the versions of Go that the fixed events of the entry id in
+// the Go vulnerability database name for the standard library or the toolchain
+func fetchVulnFixes(id string) ([]string, error) {
+	var entry struct {
+		Affected []struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			Ranges []struct {
+				Events []struct {
+					Fixed string `json:"fixed"`
+				} `json:"events"`
+			} `json:"ranges"`
+		} `json:"affected"`
+	}
+	if err := fetchVulnJSON(vulnDBURL+"/ID/"+id+".json", &entry); err != nil {
+		return nil, err
+	}
+	var fixed []string
+	for _, affected := range entry.Affected {
+		if affected.Package.Name != "stdlib" && affected.Package.Name != "toolchain" {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if v, ok := parseGoVersion(strings.TrimPrefix(event.Fixed, "v")); ok {
+					fixed = append(fixed, v.Raw)
+				}
+			}
+		}
+	}
+	return fixed, nil
+}
+
+// fetchVulnJSON decodes the document of the Go vulnerability database at url into v
+func fetchVulnJSON(url string, v any) error {
+	resp, err := httpGet(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s (status: %d)", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func archive(version, goos, goarch string) Release {
+	return Release{
+		Version: "go" + version,
+		Stable:  true,
+		Files: []ReleaseFile{{
+			Filename: "go" + version + "." + goos + "-" + goarch + ".tar.gz",
+			OS:       goos,
+			Arch:     goarch,
+			Kind:     "archive",
+		}},
+	}
+}
+
+func TestAvailableReleases(t *testing.T) {
+	releases := []Release{
+		archive("1.23.4", "linux", "amd64"),
+		archive("1.25rc1", "linux", "amd64"),
+		archive("1.24.3", "linux", "amd64"),
+		archive("1.24.2", "darwin", "arm64"),
+		archive("1.15.15", "linux", "amd64"),
+	}
+	available := availableReleases(releases, "linux", "amd64")
+	var raw []string
+	for _, v := range available {
+		raw = append(raw, v.Raw)
+	}
+	assert.Equal(t, []string{"1.25rc1", "1.24.3", "1.23.4"}, raw)
+
+	supported := supportedMinors(available)
+	assert.True(t, supported[[2]int{1, 24}])
+	assert.True(t, supported[[2]int{1, 23}])
+	assert.False(t, supported[[2]int{1, 25}])
+}
+
+func TestApplication_fetchSecurityReleases(t *testing.T) {
+	documents := map[string]string{
+		"/index/modules.json": `[
+			{"path": "stdlib", "vulns": [{"id": "GO-2024-2963", "fixed": "1.22.5"}, {"id": "GO-2021-0067", "fixed": "1.15.9"}]},
+			{"path": "toolchain", "vulns": [{"id": "GO-2023-1840", "fixed": "v1.20.5"}]},
+			{"path": "golang.org/x/net", "vulns": [{"id": "GO-2024-2687", "fixed": "0.23.0"}]}
+		]`,
+		"/ID/GO-2024-2963.json": `{"id": "GO-2024-2963", "affected": [{
+			"package": {"name": "stdlib"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.21.12"}, {"introduced": "1.22.0-0"}, {"fixed": "1.22.5"}]}]
+		}]}`,
+		"/ID/GO-2023-1840.json": `{"id": "GO-2023-1840", "affected": [{
+			"package": {"name": "toolchain"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.19.10"}, {"introduced": "1.20.0-0"}, {"fixed": "1.20.5"}]}]
+		}]}`,
+	}
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(document))
+	}))
+	defer server.Close()
+	originalVulnDBURL := vulnDBURL
+	defer func() { vulnDBURL = originalVulnDBURL }()
+	vulnDBURL = server.URL
+
+	os.Args = []string{os.Args[0]}
+	app := NewApp()
+	security, err := app.fetchSecurityReleases()
+	require.NoError(t, err)
+	assert.True(t, security["1.22.5"])
+	assert.True(t, security["1.21.12"], "a fix backported to the older minor is a security release")
+	assert.True(t, security["1.20.5"])
+	assert.True(t, security["1.19.10"])
+	assert.False(t, security["0.23.0"])
+	assert.NotContains(t, requested, "/ID/GO-2024-2687.json")
+	assert.NotContains(t, requested, "/ID/GO-2021-0067.json")
+}
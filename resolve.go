@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// minorOnlyPattern matches go directives like 1.22 that name the first release of a minor
+var minorOnlyPattern = regexp.MustCompile(`^\d+\.\d+$`)
+
+// versionSource describes the effective version of go for a directory and what decided it
+type versionSource struct {
+	// Version is the version of go that will run
+	Version string
+	// Path is the file that decided the Version, empty when it came from the environment
+	Path string
+	// Line is the line of Path that decided the Version, 0 when the whole file was read
+	Line int
+	// Reason explains the decision in a few words
+	Reason string
+	// NoInstall is true when GOTOOLCHAIN uses +path and missing versions must not be installed
+	NoInstall bool
+}
+
+func (s versionSource) String() string {
+	switch {
+	case len(s.Path) == 0:
+		return s.Reason
+	case s.Line > 0:
+		return fmt.Sprintf("%s (%s:%d)", s.Reason, s.Path, s.Line)
+	default:
+		return fmt.Sprintf("%s (%s)", s.Reason, s.Path)
+	}
+}
+
+// projectRequirement is the version of go that a go.mod or go.work file asks for
+type projectRequirement struct {
+	// Path is the go.mod or go.work file
+	Path string
+	// Minimum is the go directive, which is the lowest version able to build the project
+	Minimum string
+	// MinimumLine is the line of the go directive
+	MinimumLine int
+	// Toolchain is the toolchain directive without the go prefix, empty when absent
+	Toolchain string
+	// ToolchainLine is the line of the toolchain directive
+	ToolchainLine int
+}
+
+// resolveDirVersion finds the version of go for dir in this order:
+//
+//  1. IGO_VERSION
+//  2. GOTOOLCHAIN=local (the workspace version file) or GOTOOLCHAIN=go1.X.Y
+//  3. the nearest .go_version walking up from dir
+//  4. the nearest go.work (or GOWORK) and then go.mod, using the toolchain directive
+//     or else the newest installed version at or above the go directive
+//  5. the workspace version file
+//
+// GOTOOLCHAIN=<base>+auto and <base>+path only use the project when it needs a newer
+// version than <base>, and +path never installs a missing version. GOTOOLCHAIN=auto
+// and GOTOOLCHAIN=path keep the order above.
+func resolveDirVersion(workspace, dir string) (versionSource, error) {
+	if v := strings.TrimSpace(os.Getenv(envVersion)); len(v) > 0 {
+		return versionSource{Version: v, Reason: envVersion + " environment variable"}, nil
+	}
+	base, mode, _ := strings.Cut(strings.TrimSpace(os.Getenv("GOTOOLCHAIN")), "+")
+	if base == "auto" || base == "path" {
+		// the default of the go command, which igo treats as its own project resolution
+		base, mode = "", base
+	}
+	var baseSource *versionSource
+	switch {
+	case base == "local":
+		global, err := globalVersion(workspace)
+		if err != nil {
+			return versionSource{}, err
+		}
+		global.Reason = "GOTOOLCHAIN=local " + global.Reason
+		baseSource = &global
+	case len(base) > 0:
+		baseSource = &versionSource{Version: strings.TrimPrefix(base, "go"), Reason: "GOTOOLCHAIN environment variable"}
+	}
+	if baseSource != nil {
+		baseSource.NoInstall = mode == "path"
+		if len(mode) == 0 {
+			return *baseSource, nil
+		}
+	}
+	project, found, err := findProjectVersion(workspace, dir)
+	if err != nil {
+		return versionSource{}, err
+	}
+	switch {
+	case found && baseSource == nil:
+		project.NoInstall = mode == "path"
+		return project, nil
+	case found && newerThan(project.Version, baseSource.Version):
+		project.NoInstall = baseSource.NoInstall
+		return project, nil
+	case baseSource != nil:
+		return *baseSource, nil
+	}
+	global, err := globalVersion(workspace)
+	global.NoInstall = mode == "path"
+	return global, err
+}
+
+// findProjectVersion walks up from dir looking for .go_version, go.work and go.mod
+func findProjectVersion(workspace, dir string) (versionSource, bool, error) {
+	var goMod *projectRequirement
+	goWork := os.Getenv("GOWORK")
+	if len(goWork) > 0 && goWork != "off" {
+		req, err := readProjectRequirement(goWork)
+		if os.IsNotExist(err) {
+			return versionSource{}, false, fmt.Errorf("GOWORK=%s does not exist", goWork)
+		} else if err != nil {
+			return versionSource{}, false, err
+		}
+		return req.source(workspace), true, nil
+	}
+	for {
+		goVersionFile := filepath.Join(dir, ".go_version")
+		if b, err := os.ReadFile(goVersionFile); err == nil && goMod == nil {
+			if v := strings.TrimSpace(string(b)); len(v) > 0 {
+				return versionSource{Version: v, Path: goVersionFile, Line: 1, Reason: ".go_version"}, true, nil
+			}
+		}
+		if goWork != "off" {
+			if req, err := readProjectRequirement(filepath.Join(dir, "go.work")); err == nil {
+				return req.source(workspace), true, nil
+			}
+		}
+		if goMod == nil {
+			if req, err := readProjectRequirement(filepath.Join(dir, "go.mod")); err == nil {
+				goMod = &req
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if goMod != nil {
+		return goMod.source(workspace), true, nil
+	}
+	return versionSource{}, false, nil
+}
+
+// readProjectRequirement reads the go and toolchain directives of a go.mod or go.work file
+func readProjectRequirement(path string) (projectRequirement, error) {
+	req := projectRequirement{Path: path}
+	var err error
+	req.Minimum, req.MinimumLine, err = goModDirective(path, "go")
+	if err != nil {
+		return req, err
+	}
+	req.Toolchain, req.ToolchainLine, err = goModDirective(path, "toolchain")
+	if err != nil {
+		return req, err
+	}
+	if req.Toolchain == "default" {
+		req.Toolchain = ""
+	}
+	if len(req.Minimum) == 0 && len(req.Toolchain) == 0 {
+		return req, fmt.Errorf("%s has no go or toolchain directive", path)
+	}
+	if minorOnlyPattern.MatchString(req.Minimum) {
+		req.Minimum += ".0"
+	}
+	return req, nil
+}
+
+// source picks the toolchain directive when it is at least the go directive and
+// otherwise the newest installed version that satisfies the go directive
+func (req projectRequirement) source(workspace string) versionSource {
+	file := filepath.Base(req.Path)
+	if len(req.Toolchain) > 0 && !newerThan(req.Minimum, req.Toolchain) {
+		return versionSource{Version: req.Toolchain, Path: req.Path, Line: req.ToolchainLine, Reason: file + " toolchain directive"}
+	}
+	version := req.Minimum
+	reason := file + " go directive"
+	minimum, ok := parseGoVersion(req.Minimum)
+	if ok {
+		installed, _ := installedVersions(workspace)
+		for i := len(installed) - 1; i >= 0; i-- {
+			v, ok := parseGoVersion(installed[i])
+			if ok && !v.IsPreRelease() && v.Compare(minimum) >= 0 {
+				version = installed[i]
+				reason = fmt.Sprintf("newest installed version satisfying %s go %s", file, req.Minimum)
+				break
+			}
+		}
+	}
+	return versionSource{Version: version, Path: req.Path, Line: req.MinimumLine, Reason: reason}
+}
+
+// newerThan returns true when version a sorts after version b
+func newerThan(a, b string) bool {
+	va, okA := parseGoVersion(a)
+	vb, okB := parseGoVersion(b)
+	return okA && okB && va.Compare(vb) > 0
+}
+
+// globalVersion reads the version file of the workspace
+func globalVersion(workspace string) (versionSource, error) {
+	versionFile := filepath.Join(workspace, "version")
+	b, err := os.ReadFile(versionFile)
+	if err != nil {
+		return versionSource{}, fmt.Errorf("no global Go version installed at %s: %w", versionFile, err)
+	}
+	return versionSource{Version: strings.TrimSpace(string(b)), Path: versionFile, Line: 1, Reason: "global version"}, nil
+}
+
+// goModDirective returns the value and line number of the first directive in a
+// go.mod or go.work file, such as the go or toolchain lines
+func goModDirective(path, directive string) (string, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == directive {
+			return strings.TrimPrefix(fields[1], "go"), line, nil
+		}
+	}
+	return "", 0, scanner.Err()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDirVersion_order(t *testing.T) {
+	workspace := t.TempDir()
+	project := t.TempDir()
+	nested := filepath.Join(project, "cmd", "tool")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+	t.Setenv(envVersion, "")
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Setenv("GOWORK", "")
+
+	t.Run("no version anywhere", func(t *testing.T) {
+		_, err := resolveDirVersion(workspace, nested)
+		assert.Error(t, err)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "version"), []byte("1.24.3\n"), 0644))
+	t.Run("global version", func(t *testing.T) {
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.24.3", source.Version)
+		assert.Equal(t, filepath.Join(workspace, "version"), source.Path)
+	})
+
+	goMod := "module example.com/project\n\ngo 1.22\n"
+	require.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte(goMod), 0644))
+	t.Run("go.mod go directive", func(t *testing.T) {
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.22.0", source.Version)
+		assert.Equal(t, filepath.Join(project, "go.mod"), source.Path)
+		assert.Equal(t, 3, source.Line)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(project, "cmd", ".go_version"), []byte("1.23.4\n"), 0644))
+	t.Run(".go_version", func(t *testing.T) {
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.23.4", source.Version)
+		assert.Equal(t, ".go_version", source.Reason)
+	})
+
+	t.Run("GOTOOLCHAIN=local", func(t *testing.T) {
+		t.Setenv("GOTOOLCHAIN", "local")
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.24.3", source.Version)
+	})
+
+	t.Run("GOTOOLCHAIN=go1.21.13", func(t *testing.T) {
+		t.Setenv("GOTOOLCHAIN", "go1.21.13")
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.21.13", source.Version)
+	})
+
+	t.Run("GOTOOLCHAIN=go1.21.13+auto picks the newer .go_version", func(t *testing.T) {
+		t.Setenv("GOTOOLCHAIN", "go1.21.13+auto")
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.23.4", source.Version)
+		assert.False(t, source.NoInstall)
+	})
+
+	t.Run("GOTOOLCHAIN=go1.24.0+path keeps the newer base", func(t *testing.T) {
+		t.Setenv("GOTOOLCHAIN", "go1.24.0+path")
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.24.0", source.Version)
+		assert.True(t, source.NoInstall)
+	})
+
+	t.Run("IGO_VERSION", func(t *testing.T) {
+		t.Setenv(envVersion, "1.25rc1")
+		source, err := resolveDirVersion(workspace, nested)
+		require.NoError(t, err)
+		assert.Equal(t, "1.25rc1", source.Version)
+		assert.Empty(t, source.Path)
+	})
+}
+
+func TestResolveDirVersion_toolchain(t *testing.T) {
+	workspace := t.TempDir()
+	t.Setenv(envVersion, "")
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Setenv("GOWORK", "")
+	for _, v := range []string{"1.21.0", "1.21.13", "1.22.5", "1.25rc1"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(workspace, "versions", v), 0755))
+	}
+
+	t.Run("toolchain directive", func(t *testing.T) {
+		project := t.TempDir()
+		goMod := "module example.com/project\n\ngo 1.21\n\ntoolchain go1.22.5\n"
+		require.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte(goMod), 0644))
+		source, err := resolveDirVersion(workspace, project)
+		require.NoError(t, err)
+		assert.Equal(t, "1.22.5", source.Version)
+		assert.Equal(t, 5, source.Line)
+	})
+
+	t.Run("go directive is a minimum", func(t *testing.T) {
+		project := t.TempDir()
+		goMod := "module example.com/project\n\ngo 1.21.5\n"
+		require.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte(goMod), 0644))
+		source, err := resolveDirVersion(workspace, project)
+		require.NoError(t, err)
+		assert.Equal(t, "1.22.5", source.Version)
+	})
+
+	t.Run("go directive newer than every installed version", func(t *testing.T) {
+		project := t.TempDir()
+		goMod := "module example.com/project\n\ngo 1.24.2\n"
+		require.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte(goMod), 0644))
+		source, err := resolveDirVersion(workspace, project)
+		require.NoError(t, err)
+		assert.Equal(t, "1.24.2", source.Version)
+	})
+
+	t.Run("go.work wins over go.mod", func(t *testing.T) {
+		monorepo := t.TempDir()
+		module := filepath.Join(monorepo, "services", "api")
+		require.NoError(t, os.MkdirAll(module, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(module, "go.mod"), []byte("module api\n\ngo 1.21\n\ntoolchain go1.21.13\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(monorepo, "go.work"), []byte("go 1.22.0\n\ntoolchain go1.22.5\n\nuse ./services/api\n"), 0644))
+		source, err := resolveDirVersion(workspace, module)
+		require.NoError(t, err)
+		assert.Equal(t, "1.22.5", source.Version)
+		assert.Equal(t, filepath.Join(monorepo, "go.work"), source.Path)
+
+		t.Setenv("GOWORK", "off")
+		source, err = resolveDirVersion(workspace, module)
+		require.NoError(t, err)
+		assert.Equal(t, "1.21.13", source.Version)
+	})
+}
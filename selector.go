@@ -0,0 +1,202 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// goVersionPattern matches versions of Go with or without the go prefix, patch or pre-release
+var goVersionPattern = regexp.MustCompile(`^(?:go)?(\d+)\.(\d+)(?:\.(\d+))?(?:(rc|beta)(\d+))?$`)
+
+// exactVersionPattern matches a single Major.Minor.Patch release or a Major.MinorrcN/betaN pre-release
+var exactVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+|(rc|beta)\d+)$`)
+
+// minorSelectorPattern matches selectors like 1.23, 1.23.x, ~1.23 and ~1.23.4
+var minorSelectorPattern = regexp.MustCompile(`^(~)?(\d+)\.(\d+)(?:\.(x|\d+))?$`)
+
+// goVersion is a parsed version of Go
+type goVersion struct {
+	Major int
+	Minor int
+	Patch int
+	// Pre is rc or beta for pre-releases and empty for final releases
+	Pre string
+	// PreNum is the N of rcN or betaN
+	PreNum int
+	// Raw is the version as it was provided without the go prefix
+	Raw string
+}
+
+// parseGoVersion parses 1.23.4, go1.23.4, 1.20 and 1.25rc1 into a goVersion
+func parseGoVersion(s string) (goVersion, bool) {
+	m := goVersionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return goVersion{}, false
+	}
+	v := goVersion{Raw: strings.TrimPrefix(strings.TrimSpace(s), "go")}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if len(m[3]) > 0 {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	if len(m[4]) > 0 {
+		v.Pre = m[4]
+		v.PreNum, _ = strconv.Atoi(m[5])
+	}
+	return v, true
+}
+
+// IsPreRelease returns true for rc and beta versions
+func (v goVersion) IsPreRelease() bool {
+	return len(v.Pre) > 0
+}
+
+// preRank orders beta before rc before the final release
+func (v goVersion) preRank() int {
+	switch v.Pre {
+	case "beta":
+		return 0
+	case "rc":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Compare returns -1, 0 or +1 depending on whether v sorts before, equal or after o
+func (v goVersion) Compare(o goVersion) int {
+	if c := cmp.Compare(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.preRank(), o.preRank()); c != 0 {
+		return c
+	}
+	return cmp.Compare(v.PreNum, o.PreNum)
+}
+
+// sortGoVersions sorts versions from oldest to newest, placing anything that
+// is not a version of Go at the front in lexical order
+func sortGoVersions(versions []string) {
+	slices.SortFunc(versions, func(a, b string) int {
+		va, okA := parseGoVersion(a)
+		vb, okB := parseGoVersion(b)
+		switch {
+		case okA && okB:
+			return va.Compare(vb)
+		case okA:
+			return 1
+		case okB:
+			return -1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+}
+
+// isExactVersion returns true when selector names a single release or pre-release
+func isExactVersion(selector string) bool {
+	return exactVersionPattern.MatchString(selector)
+}
+
+// resolveVersion picks the newest version in candidates that satisfies selector.
+//
+// Selectors:
+//
+//	latest, stable  the newest version, resolveSelector narrows stable to the
+//	                releases that go.dev marks stable
+//	1.23, 1.23.x    the newest 1.23 patch release
+//	~1.22           the newest 1.22 patch release
+//	~1.22.3         the newest 1.22 patch release at or above 1.22.3
+//	1.23.4          exactly 1.23.4
+//	1.25rc1         exactly the 1.25rc1 pre-release
+//
+// Pre-releases are only ever returned when they are requested exactly.
+func resolveVersion(selector string, candidates []string) (string, error) {
+	selector = strings.TrimSpace(selector)
+	if isExactVersion(selector) {
+		return selector, nil
+	}
+	var match func(v goVersion) bool
+	switch strings.ToLower(selector) {
+	case "latest", "stable":
+		match = func(v goVersion) bool { return true }
+	default:
+		m := minorSelectorPattern.FindStringSubmatch(selector)
+		if m == nil {
+			return "", fmt.Errorf("invalid go version selector: %s (expected X.Y.Z, X.Y, X.Y.x, ~X.Y, latest or stable)", selector)
+		}
+		major, _ := strconv.Atoi(m[2])
+		minor, _ := strconv.Atoi(m[3])
+		least := 0
+		if len(m[4]) > 0 && m[4] != "x" {
+			least, _ = strconv.Atoi(m[4])
+		}
+		match = func(v goVersion) bool {
+			return v.Major == major && v.Minor == minor && v.Patch >= least
+		}
+	}
+	var best *goVersion
+	for _, candidate := range candidates {
+		v, ok := parseGoVersion(candidate)
+		if !ok || v.IsPreRelease() || !match(v) {
+			continue
+		}
+		if best == nil || v.Compare(*best) > 0 {
+			best = &v
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("no go version matches %s", selector)
+	}
+	return best.Raw, nil
+}
+
+// resolveSelector resolves the selector against the go.dev release index when
+// installing and against the versions in the Workspace() for every other command
+func (app *Application) resolveSelector(command, selector string) (string, error) {
+	if isExactVersion(selector) {
+		return selector, nil
+	}
+	var candidates []string
+	if command == "install" {
+		releases, err := app.fetchReleases()
+		if err != nil {
+			return "", err
+		}
+		// only the releases with a tarball for -goos and -goarch can be installed
+		goos, goarch := *app.Figs.String(kGoos), *app.Figs.String(kGoArch)
+		stable := strings.EqualFold(strings.TrimSpace(selector), "stable")
+		for _, release := range releases {
+			if !release.hasArchive(goos, goarch) || (stable && !release.Stable) {
+				continue
+			}
+			candidates = append(candidates, release.Version)
+		}
+	} else {
+		versions, err := app.findGoVersions()
+		if err != nil {
+			return "", err
+		}
+		candidates = versions
+	}
+	resolved, err := resolveVersion(selector, candidates)
+	if err != nil {
+		return "", err
+	}
+	if *app.Figs.Bool(kVerbose) {
+		color.Green("Resolved %s to %s", selector, resolved)
+	}
+	return resolved, nil
+}
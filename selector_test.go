@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoVersion(t *testing.T) {
+	v, ok := parseGoVersion("go1.23.4")
+	assert.True(t, ok)
+	assert.Equal(t, goVersion{Major: 1, Minor: 23, Patch: 4, Raw: "1.23.4"}, v)
+
+	v, ok = parseGoVersion("1.20")
+	assert.True(t, ok)
+	assert.Equal(t, goVersion{Major: 1, Minor: 20, Patch: 0, Raw: "1.20"}, v)
+
+	v, ok = parseGoVersion("go1.25rc1")
+	assert.True(t, ok)
+	assert.Equal(t, goVersion{Major: 1, Minor: 25, Pre: "rc", PreNum: 1, Raw: "1.25rc1"}, v)
+	assert.True(t, v.IsPreRelease())
+
+	_, ok = parseGoVersion("non-version-dir")
+	assert.False(t, ok)
+}
+
+func TestSortGoVersions(t *testing.T) {
+	versions := []string{"1.25.0", "1.9.2", "1.25rc2", "1.24.10", "1.25beta1", "1.24.2", "1.25rc1"}
+	sortGoVersions(versions)
+	assert.Equal(t, []string{"1.9.2", "1.24.2", "1.24.10", "1.25beta1", "1.25rc1", "1.25rc2", "1.25.0"}, versions)
+}
+
+func TestResolveVersion(t *testing.T) {
+	candidates := []string{"go1.25rc1", "go1.24.3", "go1.24.0", "go1.23.4", "go1.23.10", "go1.22.7", "go1.22.2", "go1.20"}
+	tests := []struct {
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{selector: "latest", want: "1.24.3"},
+		{selector: "stable", want: "1.24.3"},
+		{selector: "1.23", want: "1.23.10"},
+		{selector: "1.23.x", want: "1.23.10"},
+		{selector: "~1.22", want: "1.22.7"},
+		{selector: "~1.22.3", want: "1.22.7"},
+		{selector: "1.22.2", want: "1.22.2"},
+		{selector: "1.20", want: "1.20"},
+		{selector: "1.25rc1", want: "1.25rc1"},
+		{selector: "1.25", wantErr: true},
+		{selector: "~1.22.8", wantErr: true},
+		{selector: "1.19.x", wantErr: true},
+		{selector: "banana", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := resolveVersion(tt.selector, candidates)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplication_resolveSelector_install(t *testing.T) {
+	unstable := archive("1.24.4", "linux", "amd64")
+	unstable.Stable = false
+	releases := []Release{archive("1.25.0", "darwin", "arm64"), unstable, archive("1.24.3", "linux", "amd64")}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(releases)
+	}))
+	defer server.Close()
+	originalReleaseIndexURL := releaseIndexURL
+	defer func() { releaseIndexURL = originalReleaseIndexURL }()
+	releaseIndexURL = server.URL
+
+	os.Args = []string{os.Args[0], "-goos", "linux", "-goarch", "amd64"}
+	app := NewApp()
+	// 1.25.0 has no tarball for linux/amd64
+	got, err := app.resolveSelector("install", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "1.24.4", got)
+	got, err = app.resolveSelector("install", "stable")
+	require.NoError(t, err)
+	assert.Equal(t, "1.24.3", got)
+	_, err = app.resolveSelector("install", "1.25")
+	assert.Error(t, err)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// shells accepted by -init
+const (
+	shellSh   = "sh"
+	shellBash = "bash"
+	shellZsh  = "zsh"
+	shellFish = "fish"
+)
+
+// markers around the block that -rc manages in a shell rc file
+const (
+	managedBlockBegin = "# >>> igo >>>"
+	managedBlockEnd   = "# <<< igo <<<"
+)
+
+// initShell returns the validated -init shell
+func (app *Application) initShell() (string, error) {
+	shell := strings.ToLower(strings.TrimSpace(*app.Figs.String(cmdInit)))
+	switch shell {
+	case shellSh, shellBash, shellZsh, shellFish:
+		return shell, nil
+	}
+	return "", internal.ErrUsage{Usage: fmt.Sprintf("-%s %s|%s|%s|%s [-%s [-%s <file>] | -%s]",
+		cmdInit, shellSh, shellBash, shellZsh, shellFish, kRC, kRCFile, kRCRemove)}
+}
+
+// initEnvs returns the environment that -init exports, every path goes through the
+// workspace symlinks so that it follows -s without editing the rc file again
+func (app *Application) initEnvs() map[string]string {
+	workspace := app.Workspace()
+	return map[string]string{
+		envGoDir:       workspace,
+		GOROOT:         filepath.Join(workspace, "root"),
+		GOPATH:         filepath.Join(workspace, "path"),
+		GOBIN:          filepath.Join(workspace, "bin"),
+		GOMODCACHE:     filepath.Join(workspace, "root", "pkg", "mod"),
+		GOCACHE:        filepath.Join(workspace, "cache"),
+		GOTELEMETRYDIR: filepath.Join(workspace, "telemetry"),
+		GOSCRIPTS:      filepath.Join(workspace, "scripts"),
+		GOSHIMS:        filepath.Join(workspace, "shims"),
+	}
+}
+
+// igoExecutable returns the path of the running igo binary with symlinks resolved
+func igoExecutable() (string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the igo executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+	return self, nil
+}
+
+// initScript returns the code that shell evaluates to use igo followed by the hook of
+// -hook, the PATH entries are only added when missing so that sourcing it twice
+// changes nothing
+func (app *Application) initScript(shell string) (string, error) {
+	envs := app.initEnvs()
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	// the shims come first so that they pick the version of go for each directory
+	paths := []string{envs[GOSHIMS], envs[GOSCRIPTS], envs[GOBIN]}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# igo %s shell integration for %s\n", BinaryVersion(), shell)
+	if shell == shellFish {
+		for _, name := range names {
+			fmt.Fprintf(&b, "set -gx %s %s\n", name, fishQuote(envs[name]))
+		}
+		for _, path := range slices.Backward(paths) {
+			fmt.Fprintf(&b, "contains -- %s $PATH; or set -gx PATH %s $PATH\n", fishQuote(path), fishQuote(path))
+		}
+	} else {
+		for _, name := range names {
+			fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(envs[name]))
+		}
+		for _, path := range slices.Backward(paths) {
+			fmt.Fprintf(&b, "case \":${PATH}:\" in *:%s:*) ;; *) export PATH=%s:\"${PATH}\" ;; esac\n", shellQuote(path), shellQuote(path))
+		}
+	}
+	if !*app.Figs.Bool(kHook) {
+		return b.String(), nil
+	}
+	hook, err := app.hookScript(shell)
+	if err != nil {
+		return "", err
+	}
+	return b.String() + hook, nil
+}
+
+// shellQuote quotes s for sh, bash and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, which allows \\ and \' inside single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// rcFile returns -rc-file or the rc file that shell reads for interactive sessions
+func (app *Application) rcFile(shell string) string {
+	if file := *app.Figs.String(kRCFile); len(file) > 0 {
+		return file
+	}
+	switch shell {
+	case shellBash:
+		return filepath.Join(app.UserHomeDir, ".bashrc")
+	case shellZsh:
+		return filepath.Join(app.UserHomeDir, ".zshrc")
+	case shellFish:
+		return filepath.Join(app.UserHomeDir, ".config", "fish", "config.fish")
+	}
+	return filepath.Join(app.UserHomeDir, ".profile")
+}
+
+// rcFiles are every rc file that -rc may manage a block in
+func (app *Application) rcFiles() []string {
+	files := []string{}
+	for _, shell := range []string{shellSh, shellBash, shellZsh, shellFish} {
+		files = append(files, app.rcFile(shell))
+	}
+	return files
+}
+
+// managedBlock returns the block that -rc writes to the rc file of shell
+func (app *Application) managedBlock(shell string) ([]string, error) {
+	self, err := igoExecutable()
+	if err != nil {
+		return nil, err
+	}
+	var load string
+	if shell == shellFish {
+		load = fmt.Sprintf("%s -%s %s -%s %s | source", fishQuote(self), cmdInit, shell, kGoDir, fishQuote(app.Workspace()))
+	} else {
+		load = fmt.Sprintf("eval \"$(%s -%s %s -%s %s)\"", shellQuote(self), cmdInit, shell, kGoDir, shellQuote(app.Workspace()))
+	}
+	return []string{
+		managedBlockBegin,
+		fmt.Sprintf("# managed by igo, remove with: igo -%s %s -%s", cmdInit, shell, kRCRemove),
+		load,
+		managedBlockEnd,
+	}, nil
+}
+
+// findManagedBlock returns the lines of the managed block in lines, ok is false when
+// there is no complete block
+func findManagedBlock(lines []string) (begin, end int, ok bool) {
+	begin = slices.Index(lines, managedBlockBegin)
+	if begin < 0 {
+		return 0, 0, false
+	}
+	end = slices.Index(lines[begin:], managedBlockEnd)
+	if end < 0 {
+		return 0, 0, false
+	}
+	return begin, begin + end, true
+}
+
+// hasManagedBlock reports whether any rc file has a managed block, install leaves the
+// shell profiles alone once the user opted into -init
+func (app *Application) hasManagedBlock() bool {
+	for _, file := range app.rcFiles() {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if _, _, ok := findManagedBlock(strings.Split(string(content), "\n")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// writeManagedBlock replaces the managed block in file with block, or appends block
+// when file has none, and removes it when block is nil
+func writeManagedBlock(file string, block []string) (changed bool, err error) {
+	perm := os.FileMode(0644)
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		if block == nil {
+			return false, nil
+		}
+	} else if err != nil {
+		return false, internal.ErrFile{Path: file, Err: err, How: "os.ReadFile"}
+	} else if info, err := os.Stat(file); err == nil {
+		perm = info.Mode().Perm()
+	}
+	text := strings.TrimSuffix(string(content), "\n")
+	var lines []string
+	if len(text) > 0 {
+		lines = strings.Split(text, "\n")
+	}
+	begin, end, ok := findManagedBlock(lines)
+	switch {
+	case ok && slices.Equal(lines[begin:end+1], block):
+		return false, nil
+	case ok:
+		lines = slices.Replace(lines, begin, end+1, block...)
+	case block == nil:
+		return false, nil
+	default:
+		lines = append(lines, block...)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return false, internal.ErrFile{Path: filepath.Dir(file), Err: err, How: "os.MkdirAll"}
+	}
+	out := strings.Join(lines, "\n")
+	if len(out) > 0 {
+		out += "\n"
+	}
+	if err := os.WriteFile(file, []byte(out), perm); err != nil {
+		return false, internal.ErrFile{Path: file, Err: err, How: "os.WriteFile"}
+	}
+	return true, nil
+}
+
+// shellInit prints the -init code for a shell, or manages the block that loads it in
+// the rc file of the shell with -rc and -rc-remove
+func shellInit(app *Application) error {
+	shell, err := app.initShell()
+	if err != nil {
+		return err
+	}
+	rc, remove := *app.Figs.Bool(kRC), *app.Figs.Bool(kRCRemove)
+	if !rc && !remove {
+		script, err := app.initScript(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	}
+	file := app.rcFile(shell)
+	var block []string
+	if !remove {
+		if block, err = app.managedBlock(shell); err != nil {
+			return err
+		}
+	}
+	changed, err := writeManagedBlock(file, block)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !changed:
+		color.Green("%s is up to date", file)
+	case remove:
+		color.Green("Removed the igo block from %s", file)
+	default:
+		color.Green("Added the igo block to %s, open a new shell to use it", file)
+	}
+	return nil
+}
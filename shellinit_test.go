@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplication_initScript(t *testing.T) {
+	workspace := filepath.Join(t.TempDir(), "it's go")
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "bash", "-hook=false"}
+	app := NewApp()
+
+	script, err := app.initScript(shellBash)
+	require.NoError(t, err)
+	assert.NotContains(t, script, "_igo_hook")
+	assert.Contains(t, script, "export GOROOT='"+strings.ReplaceAll(filepath.Join(workspace, "root"), "'", `'\''`)+"'\n")
+	if sh, err := exec.LookPath("sh"); err == nil {
+		out, err := exec.Command(sh, "-c", script+script+`printf '%s\n%s' "$PATH" "$GOSHIMS"`).Output()
+		require.NoError(t, err)
+		lines := strings.Split(string(out), "\n")
+		parts := strings.Split(lines[0], ":")
+		assert.Equal(t, []string{filepath.Join(workspace, "shims"), filepath.Join(workspace, "scripts"), filepath.Join(workspace, "bin")}, parts[:3])
+		assert.Equal(t, os.Getenv("PATH"), strings.Join(parts[3:], ":"), "sourcing twice adds the paths once")
+		assert.Equal(t, filepath.Join(workspace, "shims"), lines[1])
+	}
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "fish"}
+	app = NewApp()
+	fish, err := app.initScript(shellFish)
+	require.NoError(t, err)
+	assert.Contains(t, fish, "set -gx GOSHIMS '"+strings.ReplaceAll(filepath.Join(workspace, "shims"), "'", `\'`)+"'\n")
+	assert.Contains(t, fish, "function _igo_hook --on-variable PWD\n")
+	assert.NotContains(t, fish, "export ")
+	posix, err := app.initScript(shellSh)
+	require.NoError(t, err)
+	assert.NotContains(t, posix, "_igo_hook", "sh has no event for a change of directory")
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "tcsh"}
+	_, err = NewApp().initShell()
+	assert.ErrorAs(t, err, &internal.ErrUsage{})
+}
+
+func TestWriteManagedBlock(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".config", "fish", "config.fish")
+	block := []string{managedBlockBegin, "igo -init fish | source", managedBlockEnd}
+
+	changed, err := writeManagedBlock(rc, nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoFileExists(t, rc)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(rc), 0755))
+	require.NoError(t, os.WriteFile(rc, []byte("set -gx EDITOR vi\n"), 0600))
+	changed, err = writeManagedBlock(rc, block)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = writeManagedBlock(rc, block)
+	require.NoError(t, err)
+	assert.False(t, changed, "an unchanged block is not written again")
+
+	updated := []string{managedBlockBegin, "igo -init fish -godir /opt/go | source", managedBlockEnd}
+	require.NoError(t, os.WriteFile(rc, []byte("set -gx EDITOR vi\n"+strings.Join(block, "\n")+"\nalias g git\n"), 0600))
+	_, err = writeManagedBlock(rc, updated)
+	require.NoError(t, err)
+	content, err := os.ReadFile(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "set -gx EDITOR vi\n"+strings.Join(updated, "\n")+"\nalias g git\n", string(content))
+
+	changed, err = writeManagedBlock(rc, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	content, err = os.ReadFile(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "set -gx EDITOR vi\nalias g git\n", string(content))
+	info, err := os.Stat(rc)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestShellInit_rc(t *testing.T) {
+	home := t.TempDir()
+	homeDir := UserHomeDir
+	UserHomeDir = func() (string, error) { return home, nil }
+	defer func() { UserHomeDir = homeDir }()
+	workspace := t.TempDir()
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "zsh", "-rc"}
+	app := NewApp()
+	assert.False(t, app.hasManagedBlock())
+	require.NoError(t, shellInit(app))
+	content, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "-init zsh -godir '"+workspace+"')\"\n")
+	assert.True(t, app.hasManagedBlock())
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-init", "zsh", "-rc-remove"}
+	app = NewApp()
+	require.NoError(t, shellInit(app))
+	content, err = os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Empty(t, string(content))
+	assert.False(t, app.hasManagedBlock())
+}
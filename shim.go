@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// shimNames are the toolchain binaries that CreateShims links to igo
+var shimNames = []string{"go", "gofmt"}
+
+// isShimInvocation returns true when igo was executed through a shim, either by a
+// well known toolchain name or by any name linked from the shims directory
+func isShimInvocation(argv0 string) bool {
+	name := filepath.Base(argv0)
+	for _, shim := range shimNames {
+		if name == shim {
+			return true
+		}
+	}
+	if strings.HasPrefix(name, internal.PRODUCT) {
+		return false
+	}
+	path, err := exec.LookPath(argv0)
+	return err == nil && filepath.Base(filepath.Dir(path)) == "shims"
+}
+
+// shimWorkspace finds the workspace of the shim that executed igo using
+// IGO_GODIR, then the parent of the shims directory, then ~/go
+func shimWorkspace(argv0 string) string {
+	if godir := os.Getenv(envGoDir); len(godir) > 0 {
+		return godir
+	}
+	if path, err := exec.LookPath(argv0); err == nil {
+		if abs, err := filepath.Abs(path); err == nil && filepath.Base(filepath.Dir(abs)) == "shims" {
+			return filepath.Dir(filepath.Dir(abs))
+		}
+	}
+	home, err := UserHomeDir()
+	if err != nil {
+		return filepath.Join("/", "usr", "go")
+	}
+	return filepath.Join(home, "go")
+}
+
+// shimBinary returns the binary of name for version, go and gofmt are renamed to
+// name.version by install while extra packages keep their name
+func shimBinary(workspace, version, name string) string {
+	binDir := filepath.Join(workspace, "versions", version, "go", "bin")
+	versioned := filepath.Join(binDir, name+"."+version)
+	if _, err := os.Stat(versioned); err == nil {
+		return versioned
+	}
+	return filepath.Join(binDir, name)
+}
+
+// runShim replaces igo with the toolchain binary that argv0 names for the version
+// of go that applies to the current directory, installing it first when missing
+func runShim(argv0 string, args []string) error {
+	name := filepath.Base(argv0)
+	workspace := shimWorkspace(argv0)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	source, err := resolveDirVersion(workspace, cwd)
+	if err != nil {
+		return err
+	}
+	version := source.Version
+	binary := shimBinary(workspace, version, name)
+	if _, err := os.Stat(binary); os.IsNotExist(err) && source.NoInstall {
+		return fmt.Errorf("go %s is not installed and %s forbids installing it", version, source)
+	} else if os.IsNotExist(err) {
+		if err := installMissing(workspace, version); err != nil {
+			return err
+		}
+		binary = shimBinary(workspace, version, name)
+		if _, err := os.Stat(binary); err != nil {
+			return fmt.Errorf("go %s does not provide %s: %w", version, name, err)
+		}
+	}
+	// usage is best effort and never printed since stdout and stderr belong to go
+	_ = recordUsage(workspace, version, cwd, time.Now())
+	return syscall.Exec(binary, append([]string{binary}, args...), shimEnviron(os.Environ(), workspace, version))
+}
+
+// shimEnviron returns environ with GOROOT, GOPATH, GOBIN and GOMODCACHE of version,
+// replacing the ones that the shell profiles export for the activated version
+func shimEnviron(environ []string, workspace, version string) []string {
+	return mergeEnviron(environ, versionPaths(workspace, version))
+}
+
+// installMissing installs version into workspace with a child igo that writes to
+// stderr, since stdout belongs to go or to the shell that evaluates -hook-env
+func installMissing(workspace, version string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Missing Go version %s! installing now...\n", version)
+	install := exec.Command(self, "-"+cmdInstall, version, "-"+kGoDir, workspace)
+	install.Stdout, install.Stderr = os.Stderr, os.Stderr
+	if err := install.Run(); err != nil {
+		return fmt.Errorf("failed to install Go version %s: %w", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsShimInvocation(t *testing.T) {
+	assert.True(t, isShimInvocation("go"))
+	assert.True(t, isShimInvocation("/home/tester/go/shims/gofmt"))
+	assert.False(t, isShimInvocation("igo"))
+	assert.False(t, isShimInvocation("/bin/igo-linux-amd64"))
+
+	shimsDir := filepath.Join(t.TempDir(), "shims")
+	require.NoError(t, os.MkdirAll(shimsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(shimsDir, "gopls"), []byte("#!/bin/sh"), 0755))
+	assert.True(t, isShimInvocation(filepath.Join(shimsDir, "gopls")))
+}
+
+func TestShimWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	shimsDir := filepath.Join(workspace, "shims")
+	require.NoError(t, os.MkdirAll(shimsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(shimsDir, "go"), []byte("#!/bin/sh"), 0755))
+
+	t.Setenv(envGoDir, "")
+	assert.Equal(t, workspace, shimWorkspace(filepath.Join(shimsDir, "go")))
+
+	t.Setenv("PATH", shimsDir)
+	assert.Equal(t, workspace, shimWorkspace("go"))
+
+	t.Setenv(envGoDir, "/Shared/go")
+	assert.Equal(t, "/Shared/go", shimWorkspace("go"))
+}
+
+func TestShimBinary(t *testing.T) {
+	workspace := t.TempDir()
+	binDir := filepath.Join(workspace, "versions", "1.24.3", "go", "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "go.1.24.3"), nil, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "gopls"), nil, 0755))
+
+	assert.Equal(t, filepath.Join(binDir, "go.1.24.3"), shimBinary(workspace, "1.24.3", "go"))
+	assert.Equal(t, filepath.Join(binDir, "gopls"), shimBinary(workspace, "1.24.3", "gopls"))
+}
+
+func TestShimEnviron(t *testing.T) {
+	workspace := t.TempDir()
+	// install exports the activated version through the root symlink in the profiles
+	environ := []string{"HOME=/home/me", "GOROOT=" + filepath.Join(workspace, "root"), "GOBIN=" + filepath.Join(workspace, "bin")}
+	got := shimEnviron(environ, workspace, "1.21.0")
+	versionDir := filepath.Join(workspace, "versions", "1.21.0")
+	assert.Equal(t, filepath.Join(versionDir, "go"), firstEnv(got, GOROOT))
+	assert.Equal(t, filepath.Join(versionDir, "go", "bin"), firstEnv(got, GOBIN))
+	assert.Equal(t, versionDir, firstEnv(got, GOPATH))
+	assert.Equal(t, "/home/me", firstEnv(got, "HOME"))
+	assert.Len(t, got, 5)
+}
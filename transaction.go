@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// transaction records every mutation that install makes to the workspace and the
+// shell profiles so that a failed step can undo all of them in reverse order
+type transaction struct {
+	// undo restores the state before each mutation, newest last
+	undo []func() error
+	// done cleans up after the mutations once the transaction commits
+	done []func() error
+}
+
+// onRollback registers fn to run when the transaction is rolled back
+func (tx *transaction) onRollback(fn func() error) {
+	tx.undo = append(tx.undo, fn)
+}
+
+// onCommit registers fn to run when the transaction commits
+func (tx *transaction) onCommit(fn func() error) {
+	tx.done = append(tx.done, fn)
+}
+
+// mkdirAll creates path and removes the first directory it had to create on rollback
+func (tx *transaction) mkdirAll(path string, perm os.FileMode) error {
+	created := ""
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		created = dir
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	if len(created) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(path, perm); err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return os.RemoveAll(created)
+	})
+	return nil
+}
+
+// rename moves oldPath to newPath and moves it back on rollback
+func (tx *transaction) rename(oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return os.Rename(newPath, oldPath)
+	})
+	return nil
+}
+
+// moveAside renames path out of the way until the transaction commits, which removes
+// it, or rolls back, which restores it
+func (tx *transaction) moveAside(path string) error {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return nil
+	}
+	aside := fmt.Sprintf("%s.rollback-%d", path, os.Getpid())
+	if err := tx.rename(path, aside); err != nil {
+		return err
+	}
+	tx.onCommit(func() error {
+		if err := internal.MakeDirsWritable(aside); err != nil {
+			return err
+		}
+		return os.RemoveAll(aside)
+	})
+	return nil
+}
+
+// link replaces path with a symlink to target like RemoveSymlinkOrBackupPath does,
+// restoring the previous symlink or the path.bak directory on rollback
+func (tx *transaction) link(target, path string) error {
+	if previous, err := os.Readlink(path); err == nil {
+		if err := os.Remove(path); err != nil {
+			return internal.ErrPathFailed{Path: path, Err: err}
+		}
+		tx.onRollback(func() error {
+			return os.Symlink(previous, path)
+		})
+	} else if internal.PathExists(path) {
+		if err := tx.rename(path, path+".bak"); err != nil {
+			return internal.ErrPathFailed{Path: path, Err: err, Neg: "non-"}
+		}
+	}
+	if err := os.Symlink(target, path); err != nil {
+		return err
+	}
+	tx.onRollback(func() error {
+		return os.Remove(path)
+	})
+	return nil
+}
+
+// snapshot records the contents of path, or that it does not exist, and restores
+// that on rollback
+func (tx *transaction) snapshot(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		tx.onRollback(func() error {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		})
+		return nil
+	} else if err != nil {
+		return internal.ErrFile{Path: path, Err: err, How: "os.Stat"}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return internal.ErrFile{Path: path, Err: err, How: "os.ReadFile"}
+	}
+	tx.onRollback(func() error {
+		return os.WriteFile(path, content, info.Mode().Perm())
+	})
+	return nil
+}
+
+// writeFile snapshots path and then writes data to it
+func (tx *transaction) writeFile(path string, data []byte, perm os.FileMode) error {
+	if err := tx.snapshot(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// rollback undoes every recorded mutation in reverse order and keeps going when one
+// of them fails so that as much as possible is restored
+func (tx *transaction) rollback() error {
+	var errs []error
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		if err := tx.undo[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	tx.undo, tx.done = nil, nil
+	return errors.Join(errs...)
+}
+
+// commit keeps every recorded mutation and runs the cleanups registered with onCommit
+func (tx *transaction) commit() error {
+	var errs []error
+	for _, fn := range tx.done {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	tx.undo, tx.done = nil, nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_rollback(t *testing.T) {
+	dir := t.TempDir()
+	oldTarget := filepath.Join(dir, "old")
+	newTarget := filepath.Join(dir, "new")
+	require.NoError(t, os.Mkdir(oldTarget, 0755))
+	require.NoError(t, os.Mkdir(newTarget, 0755))
+	symlinked := filepath.Join(dir, "root")
+	require.NoError(t, os.Symlink(oldTarget, symlinked))
+	directory := filepath.Join(dir, "bin")
+	require.NoError(t, os.Mkdir(directory, 0755))
+	profile := filepath.Join(dir, ".profile")
+	require.NoError(t, os.WriteFile(profile, []byte("export A=1\n"), 0600))
+	missing := filepath.Join(dir, ".zshrc.local")
+	created := filepath.Join(dir, "a", "b", "c")
+
+	tx := &transaction{}
+	require.NoError(t, tx.link(newTarget, symlinked))
+	require.NoError(t, tx.link(newTarget, directory))
+	require.NoError(t, tx.writeFile(profile, []byte("export B=2\n"), 0644))
+	require.NoError(t, tx.writeFile(missing, []byte("export C=3\n"), 0644))
+	require.NoError(t, tx.mkdirAll(created, 0755))
+	require.NoError(t, tx.moveAside(oldTarget))
+	assert.DirExists(t, directory+".bak")
+	assert.NoDirExists(t, oldTarget)
+
+	require.NoError(t, tx.rollback())
+	target, err := os.Readlink(symlinked)
+	require.NoError(t, err)
+	assert.Equal(t, oldTarget, target)
+	info, err := os.Lstat(directory)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	assert.NoDirExists(t, directory+".bak")
+	content, err := os.ReadFile(profile)
+	require.NoError(t, err)
+	assert.Equal(t, "export A=1\n", string(content))
+	info, err = os.Stat(profile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, missing)
+	assert.NoDirExists(t, filepath.Join(dir, "a"))
+	assert.DirExists(t, oldTarget)
+}
+
+func TestTransaction_commit(t *testing.T) {
+	dir := t.TempDir()
+	leftover := filepath.Join(dir, "1.20.0")
+	require.NoError(t, os.MkdirAll(filepath.Join(leftover, "go"), 0755))
+	var rolledBack bool
+
+	tx := &transaction{}
+	tx.onRollback(func() error {
+		rolledBack = true
+		return errors.New("should not run")
+	})
+	require.NoError(t, tx.moveAside(leftover))
+	require.NoError(t, tx.commit())
+	assert.False(t, rolledBack)
+	assert.NoDirExists(t, leftover)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoError(t, tx.rollback())
+	assert.False(t, rolledBack)
+}
+
+// createGoTarGz writes a go release tarball whose go binary prints output for go version
+func createGoTarGz(t *testing.T, path, output string) string {
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	files := map[string]string{
+		"go/bin/go":    "#!/bin/sh\necho '" + output + "'\n",
+		"go/bin/gofmt": "#!/bin/sh\n",
+	}
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0755,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, file.Close())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// offlineMirrors points the release index at a server without it and returns -mirrors
+// for an empty file:// mirror, so that only -sha256 knows the checksum of a tarball
+func offlineMirrors(t *testing.T) string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	originalReleaseIndexURL := releaseIndexURL
+	t.Cleanup(func() { releaseIndexURL = originalReleaseIndexURL })
+	releaseIndexURL = server.URL + "/index"
+	return "file://" + t.TempDir()
+}
+
+func TestInstall_rollback(t *testing.T) {
+	home := t.TempDir()
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	UserHomeDir = func() (string, error) { return home, nil }
+	workspace := filepath.Join(home, "go")
+	oldRoot := filepath.Join(workspace, "versions", "1.19.0", "go")
+	require.NoError(t, os.MkdirAll(oldRoot, 0755))
+	require.NoError(t, os.Symlink(oldRoot, filepath.Join(workspace, "root")))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "version"), []byte("1.19.0"), 0644))
+	profile := filepath.Join(home, ".profile")
+	require.NoError(t, os.WriteFile(profile, []byte("export EDITOR=vi\n"), 0644))
+
+	// the go binary reports the wrong version so the install fails after patching everything
+	tarball := filepath.Join(t.TempDir(), "go1.20.0.linux-amd64.tar.gz")
+	sum := createGoTarGz(t, tarball, "go version go1.19.0 linux/amd64")
+	os.Args = []string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64",
+		"-tarball", tarball, "-sha256", sum, "-extras=false", "-mirrors", offlineMirrors(t)}
+	app := NewApp()
+
+	err := install(app, "1.20.0")
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &internal.ErrVersionMismatch{})
+
+	assert.NoDirExists(t, filepath.Join(workspace, "versions", "1.20.0"))
+	entries, err := os.ReadDir(filepath.Join(workspace, "versions"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "1.19.0", entries[0].Name())
+	target, err := os.Readlink(filepath.Join(workspace, "root"))
+	require.NoError(t, err)
+	assert.Equal(t, oldRoot, target)
+	assert.NoFileExists(t, filepath.Join(workspace, "bin"))
+	assert.NoFileExists(t, filepath.Join(workspace, "path"))
+	version, err := os.ReadFile(filepath.Join(workspace, "version"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.19.0", string(version))
+	content, err := os.ReadFile(profile)
+	require.NoError(t, err)
+	assert.Equal(t, "export EDITOR=vi\n", string(content))
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc.local"))
+	assertUnlocked(t, workspaceLockPath(workspace))
+	assertUnlocked(t, versionLockPath(workspace, "1.20.0"))
+	// the verified download is kept so the next attempt does not download it again
+	assert.FileExists(t, filepath.Join(workspace, "downloads", "go1.20.0.linux-amd64.tar.gz"))
+}
+
+func TestInstall_commit(t *testing.T) {
+	home := t.TempDir()
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	UserHomeDir = func() (string, error) { return home, nil }
+	workspace := filepath.Join(home, "go")
+	// a leftover of an install that never finished is replaced
+	leftover := filepath.Join(workspace, "versions", "1.20.0", "go", "stale")
+	require.NoError(t, os.MkdirAll(leftover, 0755))
+
+	tarball := filepath.Join(t.TempDir(), "go1.20.0.linux-amd64.tar.gz")
+	sum := createGoTarGz(t, tarball, "go version go1.20.0 linux/amd64")
+	os.Args = []string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64",
+		"-tarball", tarball, "-sha256", sum, "-extras=false", "-mirrors", offlineMirrors(t)}
+	app := NewApp()
+
+	require.NoError(t, install(app, "1.20.0"))
+	assert.FileExists(t, filepath.Join(workspace, "versions", "1.20.0", "go", "bin", "go.1.20.0"))
+	assert.FileExists(t, filepath.Join(workspace, "versions", "1.20.0", "installer.lock"))
+	assert.NoDirExists(t, leftover)
+	entries, err := os.ReadDir(filepath.Join(workspace, "versions"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	target, err := os.Readlink(filepath.Join(workspace, "root"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(workspace, "versions", "1.20.0", "go"), target)
+	version, err := os.ReadFile(filepath.Join(workspace, "version"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.20.0", string(version))
+	assertUnlocked(t, workspaceLockPath(workspace))
+	assertUnlocked(t, versionLockPath(workspace, "1.20.0"))
+}
+
+func TestInstall_managedBlock(t *testing.T) {
+	home := t.TempDir()
+	origHomeDir := UserHomeDir
+	defer func() { UserHomeDir = origHomeDir }()
+	UserHomeDir = func() (string, error) { return home, nil }
+	workspace := filepath.Join(home, "go")
+	// igo is loaded with -init so install leaves the shell profiles alone
+	zshrc := []byte(strings.Join([]string{managedBlockBegin, `eval "$(igo -init zsh)"`, managedBlockEnd, ""}, "\n"))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), zshrc, 0644))
+
+	tarball := filepath.Join(t.TempDir(), "go1.20.0.linux-amd64.tar.gz")
+	sum := createGoTarGz(t, tarball, "go version go1.20.0 linux/amd64")
+	os.Args = []string{os.Args[0], "-godir", workspace, "-goos", "linux", "-goarch", "amd64",
+		"-tarball", tarball, "-sha256", sum, "-extras=false", "-mirrors", offlineMirrors(t)}
+	require.NoError(t, install(NewApp(), "1.20.0"))
+	assert.FileExists(t, filepath.Join(workspace, "versions", "1.20.0", "installer.lock"))
+	assert.NoFileExists(t, filepath.Join(home, ".profile"))
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc.local"))
+	content, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, zshrc, content)
+}
+
+// assertUnlocked checks that no PID is left in the lock file at path and that it can be locked
+func assertUnlocked(t *testing.T, path string) {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+	lock, err := lockFile(path, 0)
+	require.NoError(t, err)
+	assert.NoError(t, lock.unlock())
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andreimerlescu/igo/internal"
+)
+
+// usageRecord is when a version of go was last run through a shim or -exec and from
+// which directory
+type usageRecord struct {
+	LastUsed  time.Time `json:"last_used"`
+	Directory string    `json:"directory"`
+	Count     int64     `json:"count"`
+}
+
+// usagePath is the state file with the usageRecord of each version in the workspace
+func usagePath(workspace string) string {
+	return filepath.Join(workspace, "usage.json")
+}
+
+// readUsage returns the usageRecord of each version, which is empty before any version
+// has been run
+func readUsage(workspace string) (map[string]usageRecord, error) {
+	usage := map[string]usageRecord{}
+	content, err := os.ReadFile(usagePath(workspace))
+	if os.IsNotExist(err) {
+		return usage, nil
+	} else if err != nil {
+		return usage, internal.ErrFile{Path: usagePath(workspace), Err: err, How: "os.ReadFile"}
+	}
+	if err := json.Unmarshal(content, &usage); err != nil {
+		return map[string]usageRecord{}, internal.ErrFile{Path: usagePath(workspace), Err: err, How: "json.Unmarshal"}
+	}
+	return usage, nil
+}
+
+// usageOrEmpty returns readUsage of the workspace, a usage.json that cannot be read is
+// reported on stderr and treated as empty since it only holds the last used times
+func usageOrEmpty(workspace string) map[string]usageRecord {
+	usage, err := readUsage(workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "igo: ignoring the usage of every version: %v\n", err)
+	}
+	return usage
+}
+
+// recordUsage records that version ran in dir at now, it takes the usage lock quietly
+// since the shims share stdout and stderr with go
+func recordUsage(workspace, version, dir string, now time.Time) error {
+	path := filepath.Join(workspace, "locks", "usage.lock")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// the lock is tried once so that running go is never slowed down by another shim,
+	// whose usage is recorded instead of this one
+	if err := tryLock(f); errors.Is(err, errLockHeld) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() { _ = unlockFile(f) }()
+	// a state file that cannot be read is kept for -l and -prune to report
+	usage, err := readUsage(workspace)
+	if err != nil {
+		return err
+	}
+	record := usage[version]
+	record.LastUsed, record.Directory = now.UTC(), dir
+	record.Count++
+	usage[version] = record
+	content, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write a temporary file and rename it so that readers never see a partial file
+	tmp, err := os.CreateTemp(workspace, ".usage-*.json")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), usagePath(workspace))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUsage(t *testing.T) {
+	workspace := t.TempDir()
+	usage, err := readUsage(workspace)
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, recordUsage(workspace, "1.22.1", "/src/a", first))
+	require.NoError(t, recordUsage(workspace, "1.22.1", "/src/b", first.Add(time.Hour)))
+	require.NoError(t, recordUsage(workspace, "1.21.0", "/src/a", first))
+	usage, err = readUsage(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, usageRecord{LastUsed: first.Add(time.Hour), Directory: "/src/b", Count: 2}, usage["1.22.1"])
+	assert.Equal(t, int64(1), usage["1.21.0"].Count)
+
+	require.NoError(t, os.WriteFile(usagePath(workspace), []byte("{not json"), 0644))
+	_, err = readUsage(workspace)
+	assert.Error(t, err)
+	assert.Error(t, recordUsage(workspace, "1.21.0", "/src/c", first))
+	content, err := os.ReadFile(usagePath(workspace))
+	require.NoError(t, err)
+	assert.Equal(t, "{not json", string(content), "an unreadable usage.json is kept")
+}
+
+func TestRecordUsage_lockHeld(t *testing.T) {
+	workspace := t.TempDir()
+	lock, err := lockFile(filepath.Join(workspace, "locks", "usage.lock"), time.Second)
+	require.NoError(t, err)
+	defer func() { _ = lock.unlock() }()
+
+	start := time.Now()
+	require.NoError(t, recordUsage(workspace, "1.22.1", "/src/a", start))
+	assert.Less(t, time.Since(start), lockPollInterval, "a held lock is not waited for")
+	assert.NoFileExists(t, usagePath(workspace))
+}
+
+func TestVersionLastUsed(t *testing.T) {
+	workspace := t.TempDir()
+	versionDir := filepath.Join(workspace, "versions", "1.21.0")
+	require.NoError(t, os.MkdirAll(versionDir, 0755))
+	installed := time.Now().AddDate(0, 0, -200)
+	require.NoError(t, os.WriteFile(filepath.Join(versionDir, "installer.lock"), nil, 0644))
+	require.NoError(t, os.Chtimes(filepath.Join(versionDir, "installer.lock"), installed, installed))
+
+	got, err := versionLastUsed(workspace, "1.21.0", map[string]usageRecord{})
+	require.NoError(t, err)
+	assert.WithinDuration(t, installed, got, time.Second)
+
+	used := time.Now().AddDate(0, 0, -3)
+	got, err = versionLastUsed(workspace, "1.21.0", map[string]usageRecord{"1.21.0": {LastUsed: used}})
+	require.NoError(t, err)
+	assert.WithinDuration(t, used, got, time.Second)
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-prune", "-unused-days", "90", "-format", "json"}
+	require.NoError(t, recordUsage(workspace, "1.21.0", "/src/a", used))
+	items, err := NewApp().prunePlan(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, items, "a version that ran recently is kept")
+
+	// a corrupt usage.json falls back to the install times instead of failing
+	require.NoError(t, os.WriteFile(usagePath(workspace), []byte("{not json"), 0644))
+	items, err = NewApp().prunePlan(time.Now())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "installed on "+installed.Format(time.DateOnly), items[0].Reason)
+	records, err := NewApp().versionRecords(false)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Nil(t, records[0].LastUsed)
+}
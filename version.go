@@ -3,6 +3,8 @@ package main
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -18,6 +20,11 @@ import (
 
 var httpGet = http.Get
 var httpHead = http.Head
+var httpDo = http.DefaultClient.Do
+
+// downloadBaseURL is the default of -mirrors where the DownloadName of a Version is
+// downloaded from
+var downloadBaseURL = "https://go.dev/dl/"
 
 // Version stores the paths of the tarball and extract paths for a given version
 type Version struct {
@@ -29,6 +36,8 @@ type Version struct {
 	TarPath string
 	// Version captures the version of go in the Major.Minor.Patch format
 	Version string
+	// SHA256 is the checksum of the DownloadName published by go.dev or -sha256
+	SHA256 string
 }
 
 func (v *Version) String() string {
@@ -41,10 +50,11 @@ func (v *Version) String() string {
 	return out.String()
 }
 
-// downloadURL will take the DownloadName and acquire the tar.gz file
+// downloadURL will take the DownloadName and acquire the tar.gz file from the first
+// of the -mirrors that serves it
 func (v *Version) downloadURL(app *Application) (err error) {
 	color.Blue("Starting download of %s", v.DownloadName)
-	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	verbose := *app.Figs.Bool(kVerbose)
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime)
@@ -59,46 +69,183 @@ func (v *Version) downloadURL(app *Application) (err error) {
 		fmt.Println(v)
 	}
 
-	_, err = os.Stat(v.TarPath)
-	if os.IsExist(err) {
+	if _, statErr := os.Stat(v.TarPath); statErr == nil {
 		color.Yellow("Skipping %s: file already exists", v.DownloadName)
 		return nil
 	}
 
-	fullURL := "https://go.dev/dl/" + strings.Clone(v.DownloadName)
+	var errs []error
+	for _, mirror := range app.mirrors() {
+		if path, ok := mirrorFilePath(mirror, v.DownloadName); ok {
+			err = v.copyFrom(path)
+		} else {
+			err = v.downloadFrom(app, mirror+v.DownloadName)
+		}
+		if err == nil {
+			return nil
+		}
+		color.Yellow("Failed to download %s from %s: %v", v.DownloadName, mirror, err)
+		errs = append(errs, err)
+	}
+	return internal.ErrDownload{Name: v.DownloadName, Err: errors.Join(errs...)}
+}
+
+// downloadFrom downloads fullURL into the PartPath, resuming from its size when it
+// exists, and renames it to the TarPath once it is complete
+func (v *Version) downloadFrom(app *Application, fullURL string) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	startTime := time.Now()
+	partPath := v.PartPath()
+	var offset int64
+	if info, statErr := os.Stat(partPath); statErr == nil {
+		offset = info.Size()
+	}
+
 	if verbose {
 		color.Green("Downloading %s", fullURL)
 	}
 
-	resp, err := httpGet(fullURL)
+	resp, err := httpGetRange(fullURL, offset)
 	if err != nil {
-		color.Red("Failed to download %s: %v", v.DownloadName, err)
 		return err
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		offset = 0 // the server ignored the Range request, start over
+	case http.StatusPartialContent:
+		color.Yellow("Resuming %s from %s", v.DownloadName, humanBytes(offset))
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part file already holds every byte, verifyChecksum decides if it is good
+		return os.Rename(partPath, v.TarPath)
+	default:
 		return fmt.Errorf("HTTP status %d", resp.StatusCode)
 	}
 
-	out, err := os.Create(v.TarPath)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	out, err := os.OpenFile(partPath, flags, 0644)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	total := int64(0)
-	total, err = io.Copy(out, resp.Body)
+	size := int64(-1)
+	if resp.ContentLength > 0 {
+		size = offset + resp.ContentLength
+	}
+	progress := newProgressWriter(v.DownloadName, offset, size)
+	total, err := io.Copy(io.MultiWriter(out, progress), resp.Body)
+	progress.finish()
 	if err != nil {
 		return err
 	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, v.TarPath); err != nil {
+		return err
+	}
 	if debug {
 		color.Green("Downloaded %d bytes of %s in %v", total, v.ExtractPath, time.Since(startTime))
 	}
 	return nil
 }
 
+// copyFrom copies the tarball at path, from a file:// mirror or -tarball, into the
+// PartPath and renames it to the TarPath once it is complete
+func (v *Version) copyFrom(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(v.PartPath())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	progress := newProgressWriter(v.DownloadName, 0, info.Size())
+	_, err = io.Copy(io.MultiWriter(out, progress), in)
+	progress.finish()
+	if err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(v.PartPath(), v.TarPath)
+}
+
+// PartPath is where the TarPath is downloaded to until it is complete
+func (v *Version) PartPath() string {
+	return v.TarPath + ".part"
+}
+
+// httpGetRange uses httpGet for new downloads and httpDo with a Range header to
+// resume a download from offset
+func httpGetRange(url string, offset int64) (*http.Response, error) {
+	if offset == 0 {
+		return httpGet(url)
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	return httpDo(req)
+}
+
+// verifyChecksum compares the TarPath against the SHA256 published by go.dev or the
+// -mirrors and removes the tarball when it does not match
+func (v *Version) verifyChecksum(app *Application) error {
+	verbose := *app.Figs.Bool(kVerbose)
+	if len(v.SHA256) == 0 {
+		sum, err := app.checksum(v.DownloadName)
+		if err != nil {
+			return err
+		}
+		v.SHA256 = sum
+	} else if published, ok := app.publishedChecksum(v.DownloadName); ok && !strings.EqualFold(published, v.SHA256) {
+		// a pinned checksum stands in for go.dev and the mirrors only when they publish none
+		return internal.ErrChecksumMismatch{Path: v.DownloadName, Want: published, Got: v.SHA256, Pinned: true}
+	}
+	got, err := sha256File(v.TarPath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, v.SHA256) {
+		internal.Discard(os.Remove(v.TarPath))
+		return internal.ErrChecksumMismatch{Path: v.TarPath, Want: v.SHA256, Got: got}
+	}
+	if verbose {
+		color.Green("Verified %s sha256 %s", v.DownloadName, got)
+	}
+	return nil
+}
+
+// sha256File returns the hex encoded SHA-256 of the file at path
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", internal.ErrFile{Path: path, Err: err, How: "os.Open"}
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", internal.ErrFile{Path: path, Err: err, How: "sha256"}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // extractTarGz will take the ExtractPath and expand the DownloadName there
 func (v *Version) extractTarGz(app *Application) error {
 	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
@@ -117,7 +264,7 @@ func (v *Version) extractTarGz(app *Application) error {
 	if err != nil {
 		return fmt.Errorf("error creating gzip reader: %v", err)
 	}
-	defer internal.Capture(gzReader.Close())
+	defer gzReader.Close()
 
 	// Create tar reader
 	tarReader := tar.NewReader(gzReader)
@@ -173,7 +320,10 @@ func (v *Version) extractTarGz(app *Application) error {
 			}
 
 			// Create and write to the tarFile
-			outFile := internal.CaptureOpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
+			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return fmt.Errorf("error creating tarFile %s: %v", target, err)
+			}
 
 			// Copy the tarFile contents
 			if verbose {
@@ -186,7 +336,9 @@ func (v *Version) extractTarGz(app *Application) error {
 				_ = outFile.Close()
 				return fmt.Errorf("error writing to tarFile %s: %v", target, err)
 			}
-			internal.Capture(outFile.Close())
+			if err := outFile.Close(); err != nil {
+				return fmt.Errorf("error closing tarFile %s: %v", target, err)
+			}
 
 		default:
 			fmt.Printf("Skipping unsupported type %c in %s\n", header.Typeflag, header.Name)
@@ -4,15 +4,21 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/andreimerlescu/igo/internal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockHTTPClient struct {
@@ -67,6 +73,95 @@ func TestVersion_downloadURL(t *testing.T) {
 	assert.Equal(t, []byte("mock tarball content"), content)
 }
 
+func TestVersion_downloadURL_resume(t *testing.T) {
+	content := bytes.Repeat([]byte("go toolchain bytes "), 4096)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "go1.20.0.linux-amd64.tar.gz", time.Now(), bytes.NewReader(content))
+	}))
+	defer server.Close()
+	originalDownloadBaseURL := downloadBaseURL
+	defer func() { downloadBaseURL = originalDownloadBaseURL }()
+	downloadBaseURL = server.URL + "/"
+
+	downloadsDir := t.TempDir()
+	v := Version{
+		Version:      "1.20.0",
+		DownloadName: "go1.20.0.linux-amd64.tar.gz",
+		TarPath:      filepath.Join(downloadsDir, "go1.20.0.linux-amd64.tar.gz"),
+	}
+	os.Args = []string{os.Args[0]}
+	app := NewApp()
+
+	// an interrupted download leaves a part file behind which is not the tarball
+	require.NoError(t, os.WriteFile(v.PartPath(), content[:1000], 0644))
+	assert.NoFileExists(t, v.TarPath)
+
+	require.NoError(t, v.downloadURL(app))
+	assert.Equal(t, []string{"bytes=1000-"}, ranges)
+	assert.NoFileExists(t, v.PartPath())
+	got, err := os.ReadFile(v.TarPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	// a complete tarball is not downloaded again
+	require.NoError(t, v.downloadURL(app))
+	assert.Len(t, ranges, 1)
+}
+
+func TestVersion_verifyChecksum(t *testing.T) {
+	testDir := t.TempDir()
+	tarPath := filepath.Join(testDir, "go1.20.0.linux-amd64.tar.gz")
+	require.NoError(t, createMockTarGz(tarPath))
+	tarBytes, err := os.ReadFile(tarPath)
+	require.NoError(t, err)
+	sum := sha256.Sum256(tarBytes)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Release{{
+			Version: "go1.20.0",
+			Stable:  true,
+			Files: []ReleaseFile{{
+				Filename: "go1.20.0.linux-amd64.tar.gz",
+				OS:       "linux",
+				Arch:     "amd64",
+				SHA256:   hex.EncodeToString(sum[:]),
+				Kind:     "archive",
+			}},
+		}})
+	}))
+	defer server.Close()
+	originalReleaseIndexURL := releaseIndexURL
+	defer func() { releaseIndexURL = originalReleaseIndexURL }()
+	releaseIndexURL = server.URL
+
+	os.Args = []string{os.Args[0]}
+	app := NewApp()
+
+	t.Run("matching checksum", func(t *testing.T) {
+		v := Version{Version: "1.20.0", DownloadName: "go1.20.0.linux-amd64.tar.gz", TarPath: tarPath}
+		assert.NoError(t, v.verifyChecksum(app))
+		assert.Equal(t, hex.EncodeToString(sum[:]), v.SHA256)
+		assert.FileExists(t, tarPath)
+	})
+
+	t.Run("tampered tarball", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(tarPath, []byte("tampered"), 0644))
+		v := Version{Version: "1.20.0", DownloadName: "go1.20.0.linux-amd64.tar.gz", TarPath: tarPath}
+		err := v.verifyChecksum(app)
+		var mismatch internal.ErrChecksumMismatch
+		assert.ErrorAs(t, err, &mismatch)
+		assert.NoFileExists(t, tarPath)
+	})
+
+	t.Run("unknown tarball", func(t *testing.T) {
+		v := Version{Version: "9.9.9", DownloadName: "go9.9.9.linux-amd64.tar.gz", TarPath: tarPath}
+		err := v.verifyChecksum(app)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no checksum published")
+	})
+}
+
 func TestVersion_extractTarGz(t *testing.T) {
 	testDir := t.TempDir()
 	downloadsDir := filepath.Join(testDir, "downloads")
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/fatih/color"
+)
+
+// whichRecord resolves the version of go that the shims run in dir
+func (app *Application) whichRecord(dir string) (whichRecord, error) {
+	workspace := app.Workspace()
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return whichRecord{}, err
+	}
+	if info, err := os.Stat(abs); err != nil {
+		return whichRecord{}, internal.ErrFile{Path: abs, Err: err, How: "os.Stat"}
+	} else if !info.IsDir() {
+		return whichRecord{}, internal.ErrUsage{Usage: fmt.Sprintf("igo -%s [directory]", cmdWhich)}
+	}
+	source, err := resolveDirVersion(workspace, abs)
+	if errors.Is(err, os.ErrNotExist) {
+		return whichRecord{}, internal.ErrNoGoInstalled{Workspace: workspace}
+	} else if err != nil {
+		return whichRecord{}, fmt.Errorf("cannot resolve the version of go in %s: %w", abs, err)
+	}
+	record := whichRecord{
+		Directory: abs,
+		Version:   source.Version,
+		Reason:    source.Reason,
+		Source:    source.Path,
+		Line:      source.Line,
+		Binary:    shimBinary(workspace, source.Version, "go"),
+		NoInstall: source.NoInstall,
+	}
+	if _, err := os.Stat(record.Binary); err == nil {
+		record.Installed = true
+	} else {
+		// name the binary that install will create
+		record.Binary = filepath.Join(workspace, "versions", source.Version, "go", "bin", "go."+source.Version)
+	}
+	record.Global, _ = app.activatedVersion()
+	return record, nil
+}
+
+// which explains the version of go that runs in the directory given after the flags,
+// or the current directory, and what decided it
+func which(app *Application, args []string) error {
+	verbose, debug := *app.Figs.Bool(kVerbose), *app.Figs.Bool(kDebug)
+	if verbose {
+		color.Green(VerboseEnabled)
+	}
+	if debug {
+		color.Red(DebugEnabled)
+	}
+	format, err := app.outputFormat()
+	if err != nil {
+		return err
+	}
+	dir := "."
+	switch len(args) {
+	case 0:
+	case 1:
+		dir = args[0]
+	default:
+		return internal.ErrUsage{Usage: fmt.Sprintf("igo -%s [directory]", cmdWhich)}
+	}
+	record, err := app.whichRecord(dir)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case formatJSON, formatYAML:
+		return writeRecords(os.Stdout, format, record)
+	case formatPlain:
+		fmt.Printf("%s\t%s\t%s\t%d\t%s\t%t\n", record.Version, record.Reason, record.Source, record.Line, record.Binary, record.Installed)
+		return nil
+	}
+	color.Green("go %s in %s", record.Version, record.Directory)
+	switch {
+	case len(record.Source) == 0:
+		color.White("   Decided by: %s", record.Reason)
+	case record.Line > 0:
+		color.White("   Decided by: %s at %s:%d", record.Reason, record.Source, record.Line)
+	default:
+		color.White("   Decided by: %s at %s", record.Reason, record.Source)
+	}
+	color.White("       Binary: %s", record.Binary)
+	switch {
+	case record.Installed:
+		color.Green("    Installed: yes")
+	case record.NoInstall:
+		color.Red("    Installed: no, and GOTOOLCHAIN forbids installing it, run: igo -%s %s", cmdInstall, record.Version)
+	default:
+		color.Yellow("    Installed: no, the shims install it on first use or run: igo -%s %s", cmdInstall, record.Version)
+	}
+	if len(record.Global) > 0 && record.Global != record.Version {
+		color.White("       Global: go %s (igo -%s) is overridden here", record.Global, cmdSwitch)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/igo/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhich(t *testing.T) {
+	t.Setenv(envVersion, "")
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Setenv("GOWORK", "")
+	workspace := fakeWorkspace(t)
+	project := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte("module example.com/app\n\ngo 1.21\ntoolchain go1.21.0\n"), 0644))
+
+	os.Args = []string{os.Args[0], "-godir", workspace, "-which", "-format", "json", project}
+	app := NewApp()
+	var record whichRecord
+	require.NoError(t, json.Unmarshal([]byte(captureStdout(t, func() error { return which(app, []string{project}) })), &record))
+	assert.Equal(t, whichRecord{
+		Directory: project,
+		Version:   "1.21.0",
+		Reason:    "go.mod toolchain directive",
+		Source:    filepath.Join(project, "go.mod"),
+		Line:      4,
+		Binary:    filepath.Join(workspace, "versions", "1.21.0", "go", "bin", "go.1.21.0"),
+		Installed: true,
+		Global:    "1.22.1",
+	}, record)
+
+	t.Setenv("GOTOOLCHAIN", "go1.23.0+path")
+	require.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte("module example.com/app\n\ngo 1.24.0\n"), 0644))
+	record, err := app.whichRecord(project)
+	require.NoError(t, err)
+	assert.Equal(t, "1.24.0", record.Version)
+	assert.False(t, record.Installed)
+	assert.True(t, record.NoInstall)
+	assert.Equal(t, filepath.Join(workspace, "versions", "1.24.0", "go", "bin", "go.1.24.0"), record.Binary)
+
+	t.Setenv("GOTOOLCHAIN", "")
+	t.Chdir(project)
+	os.Args = []string{os.Args[0], "-godir", workspace, "-current", "-format", "plain"}
+	app = NewApp()
+	out := captureStdout(t, func() error { return which(app, nil) })
+	assert.Equal(t, "1.24.0\tgo.mod go directive\t"+filepath.Join(project, "go.mod")+"\t3\t"+record.Binary+"\tfalse\n", out)
+
+	assert.ErrorAs(t, which(app, []string{project, project}), &internal.ErrUsage{})
+	_, err = app.whichRecord(filepath.Join(project, "go.mod"))
+	assert.ErrorAs(t, err, &internal.ErrUsage{})
+
+	// a GOWORK that cannot be used is reported instead of claiming go is not installed
+	goWork := filepath.Join(t.TempDir(), "go.work")
+	require.NoError(t, os.WriteFile(goWork, []byte("use .\n"), 0644))
+	t.Setenv("GOWORK", goWork)
+	_, err = app.whichRecord(project)
+	assert.ErrorContains(t, err, "has no go or toolchain directive")
+	assert.NotErrorAs(t, err, &internal.ErrNoGoInstalled{})
+	t.Setenv("GOWORK", filepath.Join(t.TempDir(), "missing.work"))
+	_, err = app.whichRecord(project)
+	assert.ErrorContains(t, err, "does not exist")
+	assert.NotErrorAs(t, err, &internal.ErrNoGoInstalled{})
+
+	t.Setenv("GOWORK", "")
+	os.Args = []string{os.Args[0], "-godir", t.TempDir(), "-which"}
+	_, err = NewApp().whichRecord(t.TempDir())
+	assert.ErrorAs(t, err, &internal.ErrNoGoInstalled{})
+}